@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	internal_hackernewsscraper "github.com/Smackface/go-job-scraper/internal"
+)
+
+// runAnalyze implements the analyze command: market trends across the
+// stored months, printed as tables and saved as CSV, JSON and an SVG chart.
+//
+//	go-job-scraper analyze [-top 10] [-out trends] [-json]
+func runAnalyze(args []string) {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	dir := flags.String("dir", internal_hackernewsscraper.StoreDir(), "Directory of stored listings")
+	out := flags.String("out", "trends", "Directory to write the CSV, JSON and SVG files to")
+	top := flags.Int("top", 10, "Number of technologies and locations to report")
+	asJSON := flags.Bool("json", false, "Print the trends as JSON")
+	flags.Parse(args)
+
+	listings, err := internal_hackernewsscraper.LoadListings(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	trends := internal_hackernewsscraper.AnalyzeTrends(listings, *top)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(trends); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		fmt.Print(trends.Summary())
+	}
+
+	paths, err := internal_hackernewsscraper.WriteTrends(*out, trends)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, path := range paths {
+		fmt.Fprintf(os.Stderr, "💾 %s\n", path)
+	}
+}
@@ -13,7 +13,7 @@ if (Test-Path "windows-scraper.exe") {
 
 # Build the Go binary for Windows
 Write-Host "Compiling Go binary..." -ForegroundColor Yellow
-go build -ldflags="-s -w" -o "windows-scraper.exe" "main.go"
+go build -ldflags="-s -w" -o "windows-scraper.exe" .
 
 # Verify build success
 if (Test-Path "windows-scraper.exe") {
@@ -8,7 +8,7 @@ $env:CGO_ENABLED = "0"
 
 # Build the binary
 Write-Host "Compiling binary..." -ForegroundColor Yellow
-go build -ldflags="-s -w" -o bootstrap main.go
+go build -ldflags="-s -w" -o bootstrap .
 
 # Check if build was successful
 if (Test-Path "bootstrap") {
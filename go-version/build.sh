@@ -9,7 +9,7 @@ export GOARCH=amd64
 export CGO_ENABLED=0
 
 # Build the binary
-go build -ldflags="-s -w" -o bootstrap main.go
+go build -ldflags="-s -w" -o bootstrap .
 
 # Create deployment package
 if [ -f bootstrap ]; then
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	internal_hackernewsscraper "github.com/Smackface/go-job-scraper/internal"
+)
+
+// runEval implements the eval command: score the extractor against the gold
+// set, or with -diff compare two saved results.
+//
+//	go-job-scraper eval [-model gpt-4.1] [-base-url URL] [-gold eval/gold.jsonl]
+//	go-job-scraper eval -diff eval/runs/a.json eval/runs/b.json
+func runEval(args []string) {
+	flags := flag.NewFlagSet("eval", flag.ExitOnError)
+	gold := flags.String("gold", "eval/gold.jsonl", "Labeled comments to evaluate against")
+	model := flags.String("model", internal_hackernewsscraper.ExtractionModel, "Model to extract listings with")
+	baseURL := flags.String("base-url", "", "OpenAI-compatible endpoint to use instead of OpenAI")
+	out := flags.String("out", "eval/runs", "Directory to save the result in")
+	noCache := flags.Bool("no-cache", false, "Ignore cached model responses and request them again")
+	diff := flags.Bool("diff", false, "Compare the two result files given as arguments instead of running")
+	flags.Parse(args)
+
+	if *diff {
+		if flags.NArg() != 2 {
+			log.Fatal("eval -diff needs two result files")
+		}
+		a, err := internal_hackernewsscraper.LoadEvalResult(flags.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		b, err := internal_hackernewsscraper.LoadEvalResult(flags.Arg(1))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(internal_hackernewsscraper.DiffEval(a, b))
+		return
+	}
+
+	comments, err := internal_hackernewsscraper.LoadGoldSet(*gold)
+	if err != nil {
+		log.Fatal(err)
+	}
+	internal_hackernewsscraper.ExtractionModel = *model
+	internal_hackernewsscraper.CacheEnabled = !*noCache
+	if *baseURL != "" {
+		internal_hackernewsscraper.SetOpenAIBaseURL(*baseURL)
+	}
+
+	result, err := internal_hackernewsscraper.Evaluate(comments)
+	if err != nil {
+		log.Fatal(err)
+	}
+	result.Gold = *gold
+	fmt.Print(result.Summary())
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatal(err)
+	}
+	path := filepath.Join(*out, fmt.Sprintf("%s_%s.json", time.Now().Format("2006-01-02_15-04-05"), *model))
+	if err := internal_hackernewsscraper.SaveEvalResult(result, path); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("💾 Saved evaluation to %s\n", path)
+}
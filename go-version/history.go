@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	internal_hackernewsscraper "github.com/Smackface/go-job-scraper/internal"
+)
+
+// runHistory implements the history command: list every company in the
+// stored listings, or show one company's postings month by month.
+//
+//	go-job-scraper history [-new] [-json]
+//	go-job-scraper history -company "Acme Payments"
+func runHistory(args []string) {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	dir := flags.String("dir", internal_hackernewsscraper.StoreDir(), "Directory of stored listings")
+	company := flags.String("company", "", "Show the history of this company, by name or domain")
+	onlyNew := flags.Bool("new", false, "List only companies posting for the first time in the latest month")
+	asJSON := flags.Bool("json", false, "Print the history as JSON")
+	flags.Parse(args)
+
+	listings, err := internal_hackernewsscraper.LoadListings(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	histories := internal_hackernewsscraper.BuildHistory(listings)
+
+	var result any = histories
+	switch {
+	case *company != "":
+		h, ok := internal_hackernewsscraper.FindHistory(histories, *company)
+		if !ok {
+			log.Fatalf("no stored listings for %q", *company)
+		}
+		result = h
+		if !*asJSON {
+			fmt.Print(h.Summary())
+			return
+		}
+	case *onlyNew:
+		var fresh []internal_hackernewsscraper.CompanyHistory
+		for _, h := range histories {
+			if h.New {
+				fresh = append(fresh, h)
+			}
+		}
+		histories, result = fresh, fresh
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	fmt.Print(internal_hackernewsscraper.HistoryTable(histories))
+}
@@ -0,0 +1,223 @@
+package internal_linkedin_scraper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+// BatchMode sends Run's extraction requests through the Batch API instead
+// of one at a time. Batches take up to a day but cost half as much, which
+// suits the monthly threads.
+var BatchMode = false
+
+const (
+	batchCompletionWindow = "24h"
+
+	// batchDiscount is the share of the list price charged for batched calls
+	batchDiscount = 0.5
+)
+
+// batchPollInterval is how often a submitted batch is checked on.
+var batchPollInterval = 30 * time.Second
+
+// batchOutputLine is one line of a batch's output file.
+type batchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int                        `json:"status_code"`
+		Body       gpt.ChatCompletionResponse `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// getBatchDir returns where batch input files are kept. Uses BATCH_DIR if
+// set, otherwise /tmp in AWS Lambda, batches/ locally.
+func getBatchDir() string {
+	if dir := os.Getenv("BATCH_DIR"); dir != "" {
+		return dir
+	}
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		return "/tmp/batches"
+	}
+	return "batches"
+}
+
+// serializeBatch extracts the listings of every comment with a single batch,
+// keyed by comment ID. The result is in the same order as comments. Cached
+// responses are used as they are, and comments the batch didn't answer with
+// a valid Post go through serializeListing one by one, and errors from those
+// are returned per comment.
+func serializeBatch(ctx context.Context, client *gpt.Client, comments []Comment) ([][]Listing, []error) {
+	schema := GetListingSchema()
+	results := make([][]Listing, len(comments))
+	done := make([]bool, len(comments))
+
+	requests := make(map[string]gpt.ChatCompletionRequest)
+	indexes := make(map[string]int)
+	reservations := make(map[string]reservation)
+	batch := gpt.UploadBatchFileRequest{FileName: "listings.jsonl"}
+	prompt := renderPrompt(PromptSerialize, "")
+	for i, comment := range comments {
+		req := extractionRequest(comment.Text, prompt.Text, strictListingFormat())
+		if resp, ok := loadCachedResponse(req); ok {
+			runStats.recordCacheHit()
+			var post Post
+			if len(resp.Choices) > 0 && schema.Unmarshal(resp.Choices[0].Message.Content, &post) == nil {
+				results[i] = groundedListings(post, comment.Text, prompt.Version)
+				runStats.recordComment(len(results[i]))
+				done[i] = true
+				continue
+			}
+		}
+		reserved, err := budget.reserve(req.Model, countRequestTokens(req))
+		if err != nil {
+			runStats.recordSkipped()
+			done[i] = true
+			continue
+		}
+
+		// custom IDs must be unique within a batch
+		customID := comment.ID
+		if _, taken := indexes[customID]; taken || customID == "" {
+			customID = fmt.Sprintf("comment-%d", i)
+		}
+		indexes[customID] = i
+		requests[customID] = req
+		reservations[customID] = reserved
+		batch.AddChatCompletion(customID, req)
+	}
+
+	if len(batch.Lines) > 0 {
+		if err := runBatch(ctx, client, batch, func(line batchOutputLine) {
+			i, ok := indexes[line.CustomID]
+			if !ok || line.Response == nil || line.Response.StatusCode != 200 {
+				return
+			}
+			resp := line.Response.Body
+			req := requests[line.CustomID]
+			cost := estimateCost(req.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens) * batchDiscount
+			budget.spend(reservations[line.CustomID], resp.Usage.TotalTokens, cost)
+			delete(reservations, line.CustomID)
+			runStats.recordUsage(req.Model, resp.Usage, cost)
+
+			var post Post
+			if len(resp.Choices) == 0 || schema.Unmarshal(resp.Choices[0].Message.Content, &post) != nil {
+				return
+			}
+			storeCachedResponse(req, resp)
+			results[i] = groundedListings(post, comments[i].Text, prompt.Version)
+			runStats.recordComment(len(results[i]))
+			done[i] = true
+		}); err != nil {
+			log.Printf("[WARN] batch failed, serializing comments one by one: %v", err)
+		}
+	}
+
+	// lines the batch didn't answer book their tokens again when retried
+	for _, reserved := range reservations {
+		budget.release(reserved)
+	}
+
+	retries := 0
+	errs := make([]error, len(comments))
+	for i, comment := range comments {
+		if !done[i] {
+			retries++
+			results[i], errs[i] = serializeListing(comment.Text)
+		}
+	}
+	if retries > 0 {
+		fmt.Printf("🔁 %d comments retried outside the batch\n", retries)
+	}
+	return results, errs
+}
+
+// runBatch uploads batch, waits for it to finish and hands each line of its
+// output to handle. The JSONL input is kept in getBatchDir for reference.
+func runBatch(ctx context.Context, client *gpt.Client, batch gpt.UploadBatchFileRequest, handle func(batchOutputLine)) error {
+	if err := os.MkdirAll(getBatchDir(), 0755); err != nil {
+		return err
+	}
+	inputPath := filepath.Join(getBatchDir(), fmt.Sprintf("batch-%s.jsonl", time.Now().Format("2006-01-02_15-04-05")))
+	if err := os.WriteFile(inputPath, batch.MarshalJSONL(), 0644); err != nil {
+		return fmt.Errorf("failed to write batch file: %w", err)
+	}
+
+	fmt.Printf("📦 Submitting batch of %d requests (%s)\n", len(batch.Lines), inputPath)
+	created, err := client.CreateBatchWithUploadFile(ctx, gpt.CreateBatchWithUploadFileRequest{
+		Endpoint:               gpt.BatchEndpointChatCompletions,
+		CompletionWindow:       batchCompletionWindow,
+		UploadBatchFileRequest: batch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	status, err := waitForBatch(ctx, client, created.ID)
+	if err != nil {
+		return err
+	}
+	if status.OutputFileID == nil {
+		return fmt.Errorf("batch %s ended %s without output", status.ID, status.Status)
+	}
+
+	output, err := client.GetFileContent(ctx, *status.OutputFileID)
+	if err != nil {
+		return fmt.Errorf("failed to download batch output: %w", err)
+	}
+	defer output.Close()
+
+	scanner := bufio.NewScanner(output)
+	// a line holds a whole completion, well past the default token size
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line batchOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			log.Printf("[WARN] skipping unreadable batch output line: %v", err)
+			continue
+		}
+		if line.Error != nil {
+			log.Printf("[WARN] batch request %s failed: %s", line.CustomID, line.Error.Message)
+		}
+		handle(line)
+	}
+	return scanner.Err()
+}
+
+// waitForBatch polls the batch until it reaches a final state. Expired and
+// cancelled batches may still have partial output, so only a failed batch is
+// an error.
+func waitForBatch(ctx context.Context, client *gpt.Client, id string) (gpt.Batch, error) {
+	for {
+		status, err := client.RetrieveBatch(ctx, id)
+		if err != nil {
+			return gpt.Batch{}, fmt.Errorf("failed to check batch %s: %w", id, err)
+		}
+		counts := status.RequestCounts
+		fmt.Printf("⏳ Batch %s %s: %d/%d done, %d failed\n", id, status.Status, counts.Completed, counts.Total, counts.Failed)
+
+		switch status.Status {
+		case "completed", "expired", "cancelled":
+			return status.Batch, nil
+		case "failed":
+			return status.Batch, fmt.Errorf("batch %s failed", id)
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.Batch, ctx.Err()
+		case <-time.After(batchPollInterval):
+		}
+	}
+}
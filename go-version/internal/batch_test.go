@@ -0,0 +1,223 @@
+package internal_linkedin_scraper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+const (
+	acmePost   = `{"company":"Acme","location":"Remote","description":"Acme | Senior Go Engineer | Remote | $150k","contact":"jobs@acme.com","positions":[{"title":"Senior Go Engineer","location":"Remote","pay":"$150k","technologies":"Go","seniority":"senior","employmentType":"full-time"}]}`
+	globexPost = `{"company":"Globex","location":"Berlin","description":"Globex | Frontend Engineer | Berlin","contact":"hr@globex.io","positions":[{"title":"Frontend Engineer","location":"Berlin","pay":"","technologies":"React","seniority":"","employmentType":""}]}`
+)
+
+// fakeBatchAPI stubs the files, batches and chat completion endpoints. Batch
+// requests are answered from posts by custom ID, and IDs missing from posts
+// come back as failed lines. Chat completions always return chatPost.
+type fakeBatchAPI struct {
+	t        *testing.T
+	posts    map[string]string
+	chatPost string
+
+	mu          sync.Mutex
+	input       []byte
+	polls       int
+	chatCalls   int
+	batchWindow string
+}
+
+func (f *fakeBatchAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/files":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			f.t.Errorf("upload without file: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if purpose := r.FormValue("purpose"); purpose != "batch" {
+			f.t.Errorf("purpose = %q, want batch", purpose)
+		}
+		f.input, _ = io.ReadAll(file)
+		w.Write([]byte(`{"id":"file-in","object":"file","purpose":"batch"}`))
+
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/batches":
+		var req gpt.CreateBatchRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.InputFileID != "file-in" {
+			f.t.Errorf("input_file_id = %q, want file-in", req.InputFileID)
+		}
+		f.batchWindow = req.CompletionWindow
+		w.Write([]byte(`{"id":"batch-1","object":"batch","status":"validating"}`))
+
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/batches/batch-1":
+		f.polls++
+		if f.polls < 2 {
+			w.Write([]byte(`{"id":"batch-1","object":"batch","status":"in_progress","request_counts":{"total":2,"completed":1,"failed":0}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"batch-1","object":"batch","status":"completed","output_file_id":"file-out","request_counts":{"total":2,"completed":2,"failed":0}}`))
+
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/files/file-out/content":
+		scanner := bufio.NewScanner(strings.NewReader(string(f.input)))
+		for scanner.Scan() {
+			var line struct {
+				CustomID string `json:"custom_id"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				f.t.Errorf("bad input line: %v", err)
+				continue
+			}
+			post, ok := f.posts[line.CustomID]
+			if !ok {
+				fmt.Fprintf(w, `{"custom_id":%q,"response":null,"error":{"code":"server_error","message":"boom"}}`+"\n", line.CustomID)
+				continue
+			}
+			fmt.Fprintf(w, `{"custom_id":%q,"response":{"status_code":200,"body":%s}}`+"\n", line.CustomID, completion(post))
+		}
+
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/chat/completions":
+		f.chatCalls++
+		w.Write([]byte(completion(f.chatPost)))
+
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func completion(content string) string {
+	quoted, _ := json.Marshal(content)
+	return fmt.Sprintf(`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":%s},"finish_reason":"stop"}],"usage":{"prompt_tokens":1000,"completion_tokens":200,"total_tokens":1200}}`, quoted)
+}
+
+// useBatchFixture points the package at api and resets the run state, undoing
+// it all when the test ends.
+func useBatchFixture(t *testing.T, api *fakeBatchAPI) *gpt.Client {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	useScratchDirs(t)
+	prevClient, prevStats, prevBudget := oaiclient, runStats, budget
+	prevCache, prevPoll := CacheEnabled, batchPollInterval
+	t.Cleanup(func() {
+		oaiclient, runStats, budget = prevClient, prevStats, prevBudget
+		CacheEnabled, batchPollInterval = prevCache, prevPoll
+	})
+
+	client := newOpenAIClient("test-key", server.URL+"/v1", nil)
+	oaiclient = client
+	runStats = newRunStats()
+	budget = &runBudget{}
+	CacheEnabled = false
+	batchPollInterval = time.Millisecond
+	return client
+}
+
+func TestSerializeBatchMapsResultsToComments(t *testing.T) {
+	api := &fakeBatchAPI{t: t, posts: map[string]string{"101": acmePost, "102": globexPost}}
+	client := useBatchFixture(t, api)
+
+	comments := []Comment{
+		{ID: "101", Text: "Acme | Senior Go Engineer | Remote | $150k\nEmail jobs@acme.com"},
+		{ID: "102", Text: "Globex | Frontend Engineer | Berlin\nReact. Write to hr@globex.io"},
+	}
+	results, errs := serializeBatch(context.Background(), client, comments)
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 || len(results[0]) != 1 || len(results[1]) != 1 {
+		t.Fatalf("results = %+v, want one listing per comment", results)
+	}
+	if got := results[0][0].Company; got != "Acme" {
+		t.Errorf("comment 101 company = %q, want Acme", got)
+	}
+	if got := results[1][0].Company; got != "Globex" {
+		t.Errorf("comment 102 company = %q, want Globex", got)
+	}
+	if api.chatCalls != 0 {
+		t.Errorf("chat completions = %d, want 0", api.chatCalls)
+	}
+	if api.batchWindow != batchCompletionWindow {
+		t.Errorf("completion window = %q, want %q", api.batchWindow, batchCompletionWindow)
+	}
+	if lines := strings.Count(strings.TrimSpace(string(api.input)), "\n") + 1; lines != 2 {
+		t.Errorf("uploaded %d lines, want 2", lines)
+	}
+
+	report := runStats.Report()
+	if report.Comments != 2 || report.Listings != 2 {
+		t.Errorf("report counted %d comments, %d listings; want 2, 2", report.Comments, report.Listings)
+	}
+	want := 2 * estimateCost(gpt.GPT4oMini, 1000, 200) * batchDiscount
+	if diff := report.Cost - want; diff > 1e-12 || diff < -1e-12 {
+		t.Errorf("cost = %v, want %v at the batch discount", report.Cost, want)
+	}
+}
+
+func TestSerializeBatchRetriesFailedLines(t *testing.T) {
+	api := &fakeBatchAPI{t: t, posts: map[string]string{"101": acmePost}, chatPost: globexPost}
+	client := useBatchFixture(t, api)
+
+	comments := []Comment{
+		{ID: "101", Text: "Acme | Senior Go Engineer | Remote | $150k\nEmail jobs@acme.com"},
+		{ID: "102", Text: "Globex | Frontend Engineer | Berlin\nReact. Write to hr@globex.io"},
+	}
+	results, errs := serializeBatch(context.Background(), client, comments)
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal(err)
+	}
+
+	if api.chatCalls != 1 {
+		t.Errorf("chat completions = %d, want 1 for the failed line", api.chatCalls)
+	}
+	if len(results[1]) != 1 || results[1][0].Company != "Globex" {
+		t.Errorf("comment 102 = %+v, want the Globex listing from the retry", results[1])
+	}
+}
+
+func TestSerializeBatchStopsAtBudget(t *testing.T) {
+	text := "Acme | Senior Go Engineer | Remote | $150k\nEmail jobs@acme.com"
+	api := &fakeBatchAPI{t: t, posts: make(map[string]string), chatPost: acmePost}
+	var comments []Comment
+	for i := range 10 {
+		id := fmt.Sprint(101 + i)
+		api.posts[id] = acmePost
+		comments = append(comments, Comment{ID: id, Text: text})
+	}
+	client := useBatchFixture(t, api)
+	estimate := countRequestTokens(extractionRequest(text, renderPrompt(PromptSerialize, "").Text, strictListingFormat()))
+	budget = &runBudget{maxTokens: 3 * estimate}
+
+	results, errs := serializeBatch(context.Background(), client, comments)
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Count(strings.TrimSpace(string(api.input)), "\n") + 1; lines != 3 {
+		t.Errorf("uploaded %d lines, want the 3 that fit in the budget", lines)
+	}
+	if api.chatCalls != 0 || runStats.Skipped != 7 {
+		t.Errorf("%d chat completions, %d skipped; want none and 7", api.chatCalls, runStats.Skipped)
+	}
+	if len(results[2]) != 1 || results[3] != nil {
+		t.Errorf("results %+v, want listings for the first 3 comments only", results)
+	}
+}
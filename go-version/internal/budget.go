@@ -0,0 +1,92 @@
+package internal_linkedin_scraper
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned instead of sending a request that would take
+// the run past its token or cost budget.
+var ErrBudgetExceeded = errors.New("run budget exceeded")
+
+// runBudget caps the tokens and dollars a single run may spend. A zero
+// limit means unlimited. It is safe for concurrent use.
+type runBudget struct {
+	mu        sync.Mutex
+	maxTokens int
+	maxCost   float64
+	tokens    int
+	cost      float64
+	exceeded  bool
+}
+
+var budget = &runBudget{}
+
+// budgetFromEnv reads MAX_RUN_TOKENS and MAX_RUN_COST (dollars).
+func budgetFromEnv() *runBudget {
+	b := &runBudget{}
+	if v := os.Getenv("MAX_RUN_TOKENS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			b.maxTokens = n
+		} else {
+			log.Printf("[WARN] ignoring invalid MAX_RUN_TOKENS %q", v)
+		}
+	}
+	if v := os.Getenv("MAX_RUN_COST"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			b.maxCost = f
+		} else {
+			log.Printf("[WARN] ignoring invalid MAX_RUN_COST %q", v)
+		}
+	}
+	return b
+}
+
+// reservation is what reserve booked for a request: its prompt tokens and
+// their cost. The reply's tokens are only known once it arrives.
+type reservation struct {
+	tokens int
+	cost   float64
+}
+
+// reserve books a request to model with promptTokens against the budget,
+// so concurrent requests can't all fit in the same headroom. Once the budget
+// is hit every later request is refused too, so a run stops cleanly instead
+// of trickling on. The reservation is settled with spend, or handed back
+// with release if the request fails.
+func (b *runBudget) reserve(model string, promptTokens int) (reservation, error) {
+	r := reservation{tokens: promptTokens, cost: estimateCost(model, promptTokens, 0)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.exceeded {
+		overTokens := b.maxTokens > 0 && b.tokens+r.tokens > b.maxTokens
+		overCost := b.maxCost > 0 && b.cost+r.cost > b.maxCost
+		if overTokens || overCost {
+			b.exceeded = true
+			fmt.Printf("💸 Run budget reached (%d tokens, $%.4f spent); skipping remaining requests\n", b.tokens, b.cost)
+		}
+	}
+	if b.exceeded {
+		return reservation{}, ErrBudgetExceeded
+	}
+	b.tokens += r.tokens
+	b.cost += r.cost
+	return r, nil
+}
+
+// spend settles r with the tokens and cost the request actually used.
+func (b *runBudget) spend(r reservation, tokens int, cost float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += tokens - r.tokens
+	b.cost += cost - r.cost
+}
+
+// release hands back r for a request that got no answer.
+func (b *runBudget) release(r reservation) {
+	b.spend(r, 0, 0)
+}
@@ -0,0 +1,57 @@
+package internal_linkedin_scraper
+
+import (
+	"sync"
+	"testing"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+func TestRunBudgetBooksReservations(t *testing.T) {
+	b := &runBudget{maxTokens: 1000}
+	first, err := b.reserve(gpt.GPT4oMini, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first request is still in flight, so its tokens are taken
+	if _, err := b.reserve(gpt.GPT4oMini, 600); err != ErrBudgetExceeded {
+		t.Errorf("second reserve: err = %v, want ErrBudgetExceeded", err)
+	}
+	b.spend(first, 700, 0.01)
+	if b.tokens != 700 || b.cost != 0.01 {
+		t.Errorf("after spend: %d tokens, $%v; want the actual 700 tokens and $0.01", b.tokens, b.cost)
+	}
+
+	b = &runBudget{maxTokens: 1000}
+	failed, _ := b.reserve(gpt.GPT4oMini, 600)
+	b.release(failed)
+	if b.tokens != 0 || b.cost != 0 {
+		t.Errorf("after release: %d tokens, $%v; want nothing booked", b.tokens, b.cost)
+	}
+}
+
+func TestRunBudgetCapsConcurrentRequests(t *testing.T) {
+	calls := useFakeOpenAI(t, respond(completion(acmePost)))
+	estimate := countRequestTokens(extractionRequest(acmeComment, renderPrompt(PromptSerialize, "").Text, strictListingFormat()))
+	budget = &runBudget{maxTokens: 3 * estimate}
+
+	const postings = 50
+	var wg sync.WaitGroup
+	for range postings {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := serializeListing(acmeComment); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if *calls == 0 || *calls > 3 {
+		t.Errorf("sent %d requests, want at most the 3 that fit in the budget", *calls)
+	}
+	if want := postings - int(*calls); runStats.Skipped != want {
+		t.Errorf("skipped %d postings, want %d", runStats.Skipped, want)
+	}
+}
@@ -0,0 +1,112 @@
+package internal_linkedin_scraper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+var (
+	// CacheEnabled turns the on-disk response cache on or off.
+	CacheEnabled = true
+
+	// CacheTTL is how long a cached response is reused before it is
+	// requested again.
+	CacheTTL = 7 * 24 * time.Hour
+)
+
+// cachedResponse is the file stored for each cached request.
+type cachedResponse struct {
+	CreatedAt time.Time                  `json:"createdAt"`
+	Response  gpt.ChatCompletionResponse `json:"response"`
+}
+
+// getCacheDir returns where cached responses are kept. Uses CACHE_DIR if
+// set, otherwise /tmp in AWS Lambda, cache/ locally.
+func getCacheDir() string {
+	if dir := os.Getenv("CACHE_DIR"); dir != "" {
+		return dir
+	}
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		return "/tmp/llm-cache"
+	}
+	return "cache"
+}
+
+// cacheKey addresses a request by its content. The whole request is hashed,
+// so the model, system prompt, response schema and input all take part and a
+// change to any of them misses the cache.
+func cacheKey(req gpt.ChatCompletionRequest) string {
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func cachePath(key string) string {
+	// fan out by prefix to keep directories small
+	return filepath.Join(getCacheDir(), key[:2], key+".json")
+}
+
+// loadCachedResponse returns the cached response to req, if there is one
+// younger than CacheTTL.
+func loadCachedResponse(req gpt.ChatCompletionRequest) (gpt.ChatCompletionResponse, bool) {
+	if !CacheEnabled {
+		return gpt.ChatCompletionResponse{}, false
+	}
+	data, err := os.ReadFile(cachePath(cacheKey(req)))
+	if err != nil {
+		return gpt.ChatCompletionResponse{}, false
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return gpt.ChatCompletionResponse{}, false
+	}
+	if CacheTTL > 0 && time.Since(cached.CreatedAt) > CacheTTL {
+		return gpt.ChatCompletionResponse{}, false
+	}
+	return cached.Response, true
+}
+
+// storeCachedResponse saves resp as the answer to req. Failures only cost a
+// future cache miss, so they are logged rather than returned.
+func storeCachedResponse(req gpt.ChatCompletionRequest, resp gpt.ChatCompletionResponse) {
+	if !CacheEnabled {
+		return
+	}
+	path := cachePath(cacheKey(req))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("[WARN] failed to create cache directory: %v", err)
+		return
+	}
+	data, err := json.Marshal(cachedResponse{CreatedAt: time.Now(), Response: resp})
+	if err != nil {
+		log.Printf("[WARN] failed to encode cached response: %v", err)
+		return
+	}
+	// write a file of our own then rename it, so concurrent readers never see
+	// a partial file and concurrent writers don't write over each other
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		log.Printf("[WARN] failed to write cached response: %v", err)
+		return
+	}
+	if err = tmp.Chmod(0644); err == nil {
+		_, err = tmp.Write(data)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		log.Printf("[WARN] failed to write cached response: %v", err)
+	}
+}
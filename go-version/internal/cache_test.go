@@ -0,0 +1,128 @@
+package internal_linkedin_scraper
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+// useScratchDirs points the directories the package writes to at a fresh
+// temporary directory for the length of the test.
+func useScratchDirs(t *testing.T) {
+	t.Helper()
+	scratch := t.TempDir()
+	for env, dir := range map[string]string{
+		"BATCH_DIR":    "batches",
+		"CACHE_DIR":    "cache",
+		"LISTINGS_DIR": "listings",
+		"LOGS_DIR":     "logs",
+	} {
+		t.Setenv(env, filepath.Join(scratch, dir))
+	}
+}
+
+// useCache points the cache at a temporary directory and turns it on.
+func useCache(t *testing.T) {
+	t.Helper()
+	useScratchDirs(t)
+	prevEnabled, prevTTL := CacheEnabled, CacheTTL
+	t.Cleanup(func() { CacheEnabled, CacheTTL = prevEnabled, prevTTL })
+	CacheEnabled = true
+	CacheTTL = time.Hour
+}
+
+func cachedReply(content string) gpt.ChatCompletionResponse {
+	return gpt.ChatCompletionResponse{
+		ID:      "chatcmpl-1",
+		Choices: []gpt.ChatCompletionChoice{{Message: gpt.ChatCompletionMessage{Role: gpt.ChatMessageRoleAssistant, Content: content}}},
+	}
+}
+
+func TestCacheHitAndMiss(t *testing.T) {
+	useCache(t)
+	req := chatRequest()
+	if _, ok := loadCachedResponse(req); ok {
+		t.Fatal("hit on an empty cache")
+	}
+
+	storeCachedResponse(req, cachedReply("ok"))
+	resp, ok := loadCachedResponse(req)
+	if !ok || resp.Choices[0].Message.Content != "ok" {
+		t.Fatalf("got %+v, %v; want the stored response", resp, ok)
+	}
+
+	other := chatRequest()
+	other.Messages[0].Content = "hello"
+	if _, ok := loadCachedResponse(other); ok {
+		t.Error("hit for a request with different input")
+	}
+
+	CacheTTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if _, ok := loadCachedResponse(req); ok {
+		t.Error("hit for an entry older than CacheTTL")
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	useCache(t)
+	CacheEnabled = false
+	req := chatRequest()
+	storeCachedResponse(req, cachedReply("ok"))
+	if _, err := os.Stat(getCacheDir()); !os.IsNotExist(err) {
+		t.Errorf("stored a response with the cache off: %v", err)
+	}
+	CacheEnabled = true
+	storeCachedResponse(req, cachedReply("ok"))
+	CacheEnabled = false
+	if _, ok := loadCachedResponse(req); ok {
+		t.Error("hit with the cache off")
+	}
+}
+
+func TestCacheIgnoresCorruptEntries(t *testing.T) {
+	useCache(t)
+	req := chatRequest()
+	path := cachePath(cacheKey(req))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"createdAt":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadCachedResponse(req); ok {
+		t.Fatal("hit on a corrupt entry")
+	}
+
+	// the next answer replaces it
+	storeCachedResponse(req, cachedReply("ok"))
+	if resp, ok := loadCachedResponse(req); !ok || resp.Choices[0].Message.Content != "ok" {
+		t.Errorf("got %+v, %v; want the response stored over the corrupt entry", resp, ok)
+	}
+}
+
+func TestCacheConcurrentWriters(t *testing.T) {
+	useCache(t)
+	req := chatRequest()
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			storeCachedResponse(req, cachedReply("ok"))
+		}()
+	}
+	wg.Wait()
+
+	if _, ok := loadCachedResponse(req); !ok {
+		t.Error("miss after concurrent writes")
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(cachePath(cacheKey(req))), "*.tmp"))
+	if len(leftovers) > 0 {
+		t.Errorf("left temporary files behind: %v", leftovers)
+	}
+}
@@ -0,0 +1,83 @@
+package internal_linkedin_scraper
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+// chartColors are the line colours of TechShareChart, one per technology.
+var chartColors = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf"}
+
+// TechShareChart draws the technology mention shares of t as an SVG line
+// chart, one line per technology across the months.
+func TechShareChart(t Trends) string {
+	const (
+		width, height = 760, 400
+		left, right   = 50, 160
+		top, bottom   = 30, 40
+		plotW         = width - left - right
+		plotH         = height - top - bottom
+	)
+
+	var techs []string
+	shares := make(map[string][]float64)
+	monthIndex := make(map[string]int, len(t.Months))
+	for i, month := range t.Months {
+		monthIndex[month] = i
+	}
+	maxShare := 0.0
+	for _, s := range t.Technologies {
+		if _, ok := shares[s.Technology]; !ok {
+			techs = append(techs, s.Technology)
+			shares[s.Technology] = make([]float64, len(t.Months))
+		}
+		shares[s.Technology][monthIndex[s.Month]] = s.Share
+		maxShare = math.Max(maxShare, s.Share)
+	}
+	// round the y axis up to the next 10%
+	yMax := math.Max(0.1, math.Ceil(maxShare*10)/10)
+
+	x := func(i int) float64 {
+		if len(t.Months) < 2 {
+			return left + plotW/2
+		}
+		return left + float64(i)*plotW/float64(len(t.Months)-1)
+	}
+	y := func(share float64) float64 {
+		return top + plotH - share/yMax*plotH
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="18" font-size="14">Technology mention share by month</text>`+"\n", left)
+
+	for step := 0; step <= 5; step++ {
+		share := yMax * float64(step) / 5
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#e0e0e0"/>`+"\n", left, y(share), left+plotW, y(share))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%.0f%%</text>`+"\n", left-6, y(share)+4, share*100)
+	}
+	for i, month := range t.Months {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", x(i), height-bottom+16, month)
+	}
+
+	for i, tech := range techs {
+		color := chartColors[i%len(chartColors)]
+		points := make([]string, len(t.Months))
+		for m, share := range shares[tech] {
+			points[m] = fmt.Sprintf("%.1f,%.1f", x(m), y(share))
+		}
+		if len(points) == 1 {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`+"\n", x(0), y(shares[tech][0]), color)
+		} else {
+			fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`+"\n", color, strings.Join(points, " "))
+		}
+		legendY := top + 16*i
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`+"\n", width-right+16, legendY, color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", width-right+32, legendY+9, html.EscapeString(tech))
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package internal_linkedin_scraper
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+var (
+	spacePattern    = regexp.MustCompile(`[ \t\r\n\f]+`)
+	listItemPattern = regexp.MustCompile(`^(?:[-*•·–]|\d{1,2}[.)])\s`)
+)
+
+// commentText converts the HTML of a comment body to plain text. HN
+// separates paragraphs with <p>, which become blank lines, except between
+// list-like lines ("- Go", "2) AWS") that stay on consecutive lines.
+// Preformatted blocks keep their line breaks. Links are written as their
+// full target, since HN truncates long URLs in the link text.
+func commentText(body *goquery.Selection) string {
+	var paragraphs []string
+	var current strings.Builder
+	flush := func(pre bool) {
+		p := strings.TrimSpace(current.String())
+		if pre {
+			// keep the indentation of the first line
+			p = strings.TrimRight(strings.TrimLeft(current.String(), "\n"), " \t\n")
+		}
+		if p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+		current.Reset()
+	}
+
+	var walk func(n *html.Node, pre bool)
+	walk = func(n *html.Node, pre bool) {
+		switch n.Type {
+		case html.TextNode:
+			if pre {
+				current.WriteString(n.Data)
+			} else {
+				current.WriteString(spacePattern.ReplaceAllString(n.Data, " "))
+			}
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "p":
+				flush(pre)
+			case "br":
+				current.WriteString("\n")
+				return
+			case "pre":
+				flush(pre)
+				pre = true
+			case "a":
+				current.WriteString(linkText(n))
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, pre)
+		}
+		if n.Type == html.ElementNode && (n.Data == "p" || n.Data == "pre") {
+			flush(pre)
+		}
+	}
+	for _, n := range body.Nodes {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, false)
+		}
+	}
+	flush(false)
+
+	var b strings.Builder
+	for i, p := range paragraphs {
+		if i > 0 {
+			if listItemPattern.MatchString(p) && listItemPattern.MatchString(lastLine(paragraphs[i-1])) {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(trimLines(p))
+	}
+	return b.String()
+}
+
+// linkText renders an anchor as its target, keeping the link text too when
+// it says something the URL doesn't, as in "our careers page (https://…)".
+func linkText(a *html.Node) string {
+	text := strings.TrimSpace(spacePattern.ReplaceAllString(goquery.NewDocumentFromNode(a).Text(), " "))
+	var href string
+	for _, attr := range a.Attr {
+		if attr.Key == "href" {
+			href = strings.TrimSpace(attr.Val)
+		}
+	}
+	switch {
+	case href == "" || strings.HasPrefix(href, "javascript:"):
+		return text
+	case text == "" || text == href || strings.HasSuffix(text, "...") && strings.HasPrefix(href, strings.TrimSuffix(text, "...")):
+		return href
+	case strings.HasPrefix(href, "mailto:") && strings.EqualFold(text, strings.TrimPrefix(href, "mailto:")):
+		return text
+	}
+	return text + " (" + href + ")"
+}
+
+func lastLine(s string) string {
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// trimLines trims trailing spaces from every line of s.
+func trimLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,65 @@
+package internal_linkedin_scraper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCommentText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			"paragraphs",
+			`Acme | Go Engineer | Remote<p>We build payments.<p>Email jobs@acme.example`,
+			"Acme | Go Engineer | Remote\n\nWe build payments.\n\nEmail jobs@acme.example",
+		},
+		{
+			"truncated link keeps the full target",
+			`Apply: <a href="https://jobs.acme.example/engineering/go-engineer-remote-us" rel="nofollow">https://jobs.acme.example/engineering/go-engin...</a>`,
+			"Apply: https://jobs.acme.example/engineering/go-engineer-remote-us",
+		},
+		{
+			"link text that isn't the URL",
+			`See <a href="https://acme.example/careers">our careers page</a>.`,
+			"See our careers page (https://acme.example/careers).",
+		},
+		{
+			"mailto link",
+			`<a href="mailto:jobs@acme.example">jobs@acme.example</a>`,
+			"jobs@acme.example",
+		},
+		{
+			"list-like lines stay together",
+			`Stack:<p>- Go<p>- React<p>* AWS<p>Apply below`,
+			"Stack:\n\n- Go\n- React\n* AWS\n\nApply below",
+		},
+		{
+			"preformatted block",
+			`Roles:<pre><code>  1. Backend
+  2. Frontend
+</code></pre>Thanks`,
+			"Roles:\n\n  1. Backend\n  2. Frontend\n\nThanks",
+		},
+		{
+			"entities and whitespace",
+			"You&#x27;ll   own\n services &amp; <i>on-call</i>",
+			"You'll own services & on-call",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<div class="commtext c00">` + tt.html + `</div>`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := commentText(doc.Find(".commtext")); got != tt.want {
+				t.Errorf("commentText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package internal_linkedin_scraper
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Company is a hiring company recognised across listings: one entity for
+// every spelling of its name and every domain it posts from.
+type Company struct {
+	// Key identifies the company: its normalized name, or its first domain
+	// if no listing named it
+	Key     string   `json:"key"`
+	Name    string   `json:"name"`
+	Names   []string `json:"names"`
+	Domains []string `json:"domains"`
+}
+
+var (
+	companyParenPattern = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+	companyNoisePattern = regexp.MustCompile(`[^\p{L}\p{N}&+]+`)
+
+	// companySuffixes are legal forms left off when comparing names. Forms
+	// that are also words, like "Co" or "AS", stay: "The Browser Company"
+	// isn't "browser", nor "AS Labs" "labs".
+	companySuffixes = []string{"inc", "incorporated", "llc", "ltd", "limited", "gmbh", "ag", "sa", "sas", "sarl", "bv", "nv", "corp", "corporation", "plc", "pty", "oy", "srl", "kk", "pbc"}
+
+	// freeMailDomains and sharedHosts say nothing about who is hiring
+	freeMailDomains = []string{"gmail.com", "googlemail.com", "outlook.com", "hotmail.com", "live.com", "yahoo.com", "icloud.com", "me.com", "protonmail.com", "proton.me", "pm.me", "fastmail.com", "hey.com", "aol.com"}
+	sharedHosts     = []string{"github.com", "linkedin.com", "docs.google.com", "forms.gle", "goo.gl", "bit.ly", "notion.site", "notion.so", "ycombinator.com", "twitter.com", "x.com", "medium.com", "calendly.com", "typeform.com", "airtable.com", "tally.so", "youtube.com", "substack.com"}
+)
+
+// NormalizeCompanyName reduces a company name to a form that stays the same
+// across the ways posters write it: "Acme, Inc. (YC W21)", "ACME" and
+// "acme.io" all become "acme".
+func NormalizeCompanyName(name string) string {
+	lower := strings.ToLower(companyParenPattern.ReplaceAllString(name, " "))
+	lower = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lower), "https://"), "http://")
+	// a name written as a domain
+	if host, _, _ := strings.Cut(lower, "/"); !strings.Contains(host, " ") && strings.Count(host, ".") > 0 {
+		lower, _, _ = strings.Cut(registrableDomain(strings.TrimPrefix(host, "www.")), ".")
+	}
+
+	words := strings.Fields(companyNoisePattern.ReplaceAllString(lower, " "))
+	words = slices.DeleteFunc(words, func(w string) bool {
+		return slices.Contains(companySuffixes, w) || w == "&" || w == "+"
+	})
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// companyDomains returns the domains a listing's contacts point at. Job
+// boards shared by many companies count as "<board>/<company>", and free
+// mail providers and other shared hosts are left out.
+func companyDomains(l Listing) []string {
+	contacts := l.Contacts
+	if len(contacts) == 0 {
+		contacts = ExtractContacts(l.Contact)
+	}
+
+	var domains []string
+	add := func(d string) {
+		if d != "" && !slices.Contains(domains, d) {
+			domains = append(domains, d)
+		}
+	}
+	for _, c := range contacts {
+		if !c.Valid {
+			continue
+		}
+		switch c.Kind {
+		case ContactEmail:
+			domain := strings.ToLower(c.Value[strings.LastIndex(c.Value, "@")+1:])
+			if !slices.Contains(freeMailDomains, domain) {
+				add(registrableDomain(domain))
+			}
+		case ContactURL, ContactATS:
+			u, err := url.Parse(c.Value)
+			if err != nil {
+				continue
+			}
+			host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+			if c.Kind == ContactATS {
+				add(atsCompany(host, u.Path))
+				continue
+			}
+			domain := registrableDomain(host)
+			if !slices.Contains(sharedHosts, host) && !slices.Contains(sharedHosts, domain) {
+				add(domain)
+			}
+		}
+	}
+	return domains
+}
+
+// atsCompany names the company behind a job board link. Boards put it
+// either in the subdomain (acme.recruitee.com) or first in the path
+// (jobs.lever.co/acme, ycombinator.com/companies/acme/jobs). Links to a
+// single job by ID, like workatastartup.com/jobs/123, name no company.
+func atsCompany(host, path string) string {
+	domain := registrableDomain(host)
+	segments := strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+	if len(segments) > 0 && segments[0] == "companies" {
+		segments = segments[1:]
+	}
+	sub := strings.TrimSuffix(host, "."+domain)
+	switch {
+	case sub != host && sub != "" && !slices.Contains([]string{"jobs", "boards", "apply", "careers", "job-boards", "app"}, sub):
+		return domain + "/" + sub
+	case len(segments) > 0 && !slices.Contains([]string{"jobs", "job", "apply", "careers"}, strings.ToLower(segments[0])):
+		return domain + "/" + strings.ToLower(segments[0])
+	}
+	return ""
+}
+
+// registrableDomain trims host to the name a company registers, like
+// "acme.example" for "jobs.eu.acme.example" or "acme.co.uk" for
+// "careers.acme.co.uk", going by the public suffix list. A host that is a
+// public suffix itself is returned as it is.
+func registrableDomain(host string) string {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+// ResolveCompanies groups listings into companies. Listings are the same
+// company when their names normalize alike or they share a domain, and the
+// grouping is transitive. It returns the companies, sorted by key, and for
+// each listing the index of its company, or -1 if the listing has neither a
+// name nor a domain to go by.
+func ResolveCompanies(listings []Listing) ([]Company, []int) {
+	parent := make([]int, len(listings))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	names := make([]string, len(listings))
+	domains := make([][]string, len(listings))
+	owner := make(map[string]int)
+	link := func(key string, i int) {
+		if j, ok := owner[key]; ok {
+			parent[find(i)] = find(j)
+			return
+		}
+		owner[key] = i
+	}
+	for i, l := range listings {
+		names[i] = NormalizeCompanyName(l.Company)
+		domains[i] = companyDomains(l)
+		if len(names[i]) > 1 {
+			link("name:"+names[i], i)
+		}
+		for _, d := range domains[i] {
+			link("domain:"+d, i)
+		}
+	}
+
+	// collect every group, naming it by its most used spelling
+	groups := make(map[int]*Company)
+	spellings := make(map[int]map[string]int)
+	var roots []int
+	for i, l := range listings {
+		if len(names[i]) <= 1 && len(domains[i]) == 0 {
+			continue
+		}
+		root := find(i)
+		c, ok := groups[root]
+		if !ok {
+			c = &Company{}
+			groups[root] = c
+			spellings[root] = make(map[string]int)
+			roots = append(roots, root)
+		}
+		if name := strings.TrimSpace(l.Company); name != "" {
+			if spellings[root][name] == 0 {
+				c.Names = append(c.Names, name)
+			}
+			spellings[root][name]++
+		}
+		if c.Key == "" && len(names[i]) > 1 {
+			c.Key = names[i]
+		}
+		for _, d := range domains[i] {
+			if !slices.Contains(c.Domains, d) {
+				c.Domains = append(c.Domains, d)
+			}
+		}
+	}
+	for _, root := range roots {
+		c := groups[root]
+		for _, name := range c.Names {
+			if spellings[root][name] > spellings[root][c.Name] {
+				c.Name = name
+			}
+		}
+		sort.Strings(c.Domains)
+		if c.Key == "" && len(c.Domains) > 0 {
+			c.Key = c.Domains[0]
+		}
+		if c.Name == "" {
+			c.Name = c.Key
+		}
+	}
+
+	sort.Slice(roots, func(a, b int) bool { return groups[roots[a]].Key < groups[roots[b]].Key })
+	companies := make([]Company, len(roots))
+	position := make(map[int]int, len(roots))
+	for i, root := range roots {
+		companies[i] = *groups[root]
+		position[root] = i
+	}
+	index := make([]int, len(listings))
+	for i := range listings {
+		index[i] = -1
+		if p, ok := position[find(i)]; ok {
+			index[i] = p
+		}
+	}
+	return companies, index
+}
@@ -0,0 +1,208 @@
+package internal_linkedin_scraper
+
+import (
+	"html"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ContactPoint is a single way to apply, pulled out of a Listing's text.
+type ContactPoint struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+	ATS   string `json:"ats,omitempty"`
+	Valid bool   `json:"valid"`
+}
+
+// Contact kinds returned by ExtractContacts
+const (
+	ContactEmail = "email"
+	ContactURL   = "url"
+	ContactATS   = "ats"
+)
+
+var (
+	// obfuscatedEmailPattern matches "jobs [at] example (dot) com" and friends.
+	// Group 2 is the separator: a plain " at " is prose ("our team at
+	// acme.io") unless the dot is obfuscated too.
+	obfuscatedEmailPattern = regexp.MustCompile(`(?i)([a-z0-9][a-z0-9._%+\-]*)\s*(@|[\[({<]\s*at\s*[\])}>]|\s+at\s+)\s*([a-z0-9\-]+(?:\s*(?:\.|[\[({<]\s*dot\s*[\])}>]|\s+dot\s+)\s*[a-z0-9\-]+)+)`)
+	plainEmailPattern      = regexp.MustCompile(`(?i)[a-z0-9][a-z0-9._%+\-]*@[a-z0-9\-]+(?:\.[a-z0-9\-]+)+`)
+	reversedEmailPattern   = regexp.MustCompile(`(?i)[a-z0-9.\-]+@[a-z0-9._%+\-]+`)
+	dotPattern             = regexp.MustCompile(`(?i)\s*(?:[\[({<]\s*dot\s*[\])}>]|\s+dot\s+)\s*`)
+	// urlPattern matches links with a scheme or "www.", and bare domains
+	// followed by a path like "acme.com/jobs"
+	urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'\x60]+|\b[a-z0-9][a-z0-9\-]*(?:\.[a-z0-9\-]+)+/[^\s<>"'\x60]*`)
+	tldPattern = regexp.MustCompile(`(?i)^[a-z]{2,24}$`)
+
+	// atsHosts maps applicant tracking system domains to their name
+	atsHosts = []struct{ suffix, name string }{
+		{"greenhouse.io", "greenhouse"},
+		{"lever.co", "lever"},
+		{"ashbyhq.com", "ashby"},
+		{"workable.com", "workable"},
+		{"recruitee.com", "recruitee"},
+		{"breezy.hr", "breezy"},
+		{"bamboohr.com", "bamboohr"},
+		{"smartrecruiters.com", "smartrecruiters"},
+		{"teamtailor.com", "teamtailor"},
+		{"personio.de", "personio"},
+		{"personio.com", "personio"},
+		{"jobvite.com", "jobvite"},
+		{"myworkdayjobs.com", "workday"},
+		{"rippling.com", "rippling"},
+		{"wellfound.com", "wellfound"},
+		{"workatastartup.com", "workatastartup"},
+	}
+)
+
+// ExtractContacts finds every email address and application link in text,
+// decoding HTML entities and common obfuscations like "jobs [at] example
+// (dot) com" or addresses written backwards. Links come before emails and
+// duplicates are dropped.
+func ExtractContacts(text string) []ContactPoint {
+	text = html.UnescapeString(text)
+	var contacts []ContactPoint
+	seen := make(map[string]bool)
+	add := func(c ContactPoint) {
+		key := c.Kind + ":" + strings.ToLower(c.Value)
+		if c.Value == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		contacts = append(contacts, c)
+	}
+
+	// URLs first, so the emails pass doesn't mistake a path for an address
+	for _, raw := range urlPattern.FindAllString(text, -1) {
+		c := classifyURL(trimURL(raw))
+		// a bare "node.js/react" isn't a link
+		if !c.Valid && !hasScheme(raw) {
+			continue
+		}
+		add(c)
+	}
+	withoutURLs := urlPattern.ReplaceAllString(text, " ")
+
+	// "moc.elpmaxe@sboj" only makes sense read backwards
+	for _, raw := range reversedEmailPattern.FindAllString(withoutURLs, -1) {
+		address := strings.ToLower(strings.Trim(raw, ".-"))
+		if reversed := reverse(address); !validEmail(address) && validEmail(reversed) {
+			add(ContactPoint{Kind: ContactEmail, Value: reversed, Valid: true})
+		}
+	}
+
+	// plain addresses next, so "email me at a.b@acme.com" isn't read as
+	// the obfuscated "me at a.b"
+	var withoutEmails strings.Builder
+	last := 0
+	for _, loc := range plainEmailPattern.FindAllStringIndex(withoutURLs, -1) {
+		address := strings.ToLower(strings.Trim(withoutURLs[loc[0]:loc[1]], ".-"))
+		if validEmail(address) {
+			add(ContactPoint{Kind: ContactEmail, Value: address, Valid: true})
+			withoutEmails.WriteString(withoutURLs[last:loc[0]] + " ")
+			last = loc[1]
+		}
+	}
+	withoutEmails.WriteString(withoutURLs[last:])
+
+	for _, m := range obfuscatedEmailPattern.FindAllStringSubmatch(withoutEmails.String(), -1) {
+		local, sep, domain := m[1], strings.TrimSpace(strings.ToLower(m[2])), m[3]
+		if sep == "at" && !dotPattern.MatchString(domain) {
+			continue
+		}
+		address := strings.ToLower(local + "@" + dotPattern.ReplaceAllString(domain, "."))
+		address = strings.Trim(address, ".-")
+		if !validEmail(address) && validEmail(reverse(address)) {
+			continue
+		}
+		add(ContactPoint{Kind: ContactEmail, Value: address, Valid: validEmail(address)})
+	}
+
+	return contacts
+}
+
+// PrimaryContact returns the first valid email or link, preferring direct
+// emails over ATS links over other URLs.
+func PrimaryContact(contacts []ContactPoint) (ContactPoint, bool) {
+	for _, kind := range []string{ContactEmail, ContactATS, ContactURL} {
+		for _, c := range contacts {
+			if c.Kind == kind && c.Valid {
+				return c, true
+			}
+		}
+	}
+	return ContactPoint{}, false
+}
+
+func classifyURL(raw string) ContactPoint {
+	c := ContactPoint{Kind: ContactURL, Value: raw}
+	bare := !hasScheme(raw)
+	if bare {
+		c.Value = "https://" + raw
+	}
+	u, err := url.Parse(c.Value)
+	if err != nil || !strings.Contains(u.Hostname(), ".") {
+		return c
+	}
+	host := strings.ToLower(u.Hostname())
+	// without a scheme only a real public suffix makes it a link
+	if suffix, icann := publicsuffix.PublicSuffix(host); bare && !strings.HasPrefix(host, "www.") && (!icann || suffix == host) {
+		return c
+	}
+	c.Valid = true
+	for _, ats := range atsHosts {
+		if host == ats.suffix || strings.HasSuffix(host, "."+ats.suffix) {
+			c.Kind, c.ATS = ContactATS, ats.name
+			break
+		}
+	}
+	// YC's company pages and job boards live under ycombinator.com/companies/<name>
+	if (host == "ycombinator.com" || host == "www.ycombinator.com") && strings.HasPrefix(u.Path, "/companies/") {
+		c.Kind, c.ATS = ContactATS, "ycombinator"
+	}
+	return c
+}
+
+func hasScheme(raw string) bool {
+	lower := strings.ToLower(raw)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// trimURL drops trailing punctuation picked up from the surrounding prose,
+// keeping a closing parenthesis only if the URL opened one.
+func trimURL(raw string) string {
+	for len(raw) > 0 {
+		last := raw[len(raw)-1]
+		switch {
+		case strings.ContainsRune(".,;:!?'\"", rune(last)):
+			raw = raw[:len(raw)-1]
+		case last == ')' && strings.Count(raw, "(") < strings.Count(raw, ")"):
+			raw = raw[:len(raw)-1]
+		default:
+			return raw
+		}
+	}
+	return raw
+}
+
+func validEmail(address string) bool {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address {
+		return false
+	}
+	at := strings.LastIndex(address, "@")
+	labels := strings.Split(address[at+1:], ".")
+	return len(labels) > 1 && tldPattern.MatchString(labels[len(labels)-1])
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
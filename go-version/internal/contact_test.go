@@ -0,0 +1,62 @@
+package internal_linkedin_scraper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractContacts(t *testing.T) {
+	email := func(address string) ContactPoint {
+		return ContactPoint{Kind: ContactEmail, Value: address, Valid: true}
+	}
+	ats := func(link, name string) ContactPoint {
+		return ContactPoint{Kind: ContactATS, Value: link, ATS: name, Valid: true}
+	}
+	tests := []struct {
+		text string
+		want []ContactPoint
+	}{
+		{"Email a.b@acme.com", []ContactPoint{email("a.b@acme.com")}},
+		{"Email me at a.b@acme.com", []ContactPoint{email("a.b@acme.com")}},
+		{"jobs [at] acme (dot) com", []ContactPoint{email("jobs@acme.com")}},
+		{"hiring{at}globex.example", []ContactPoint{email("hiring@globex.example")}},
+		{"reach out: jobs at acme dot io", []ContactPoint{email("jobs@acme.io")}},
+		{"careers <at> initech <dot> co <dot> uk", []ContactPoint{email("careers@initech.co.uk")}},
+		// written backwards to fool scrapers
+		{"moc.hcetini@sboj", []ContactPoint{email("jobs@initech.com")}},
+		// HN escapes slashes in links as entities
+		{"https:&#x2F;&#x2F;boards.greenhouse.io&#x2F;acme&#x2F;jobs&#x2F;123", []ContactPoint{ats("https://boards.greenhouse.io/acme/jobs/123", "greenhouse")}},
+		{"apply: https://jobs.lever.co/acme/abc.", []ContactPoint{ats("https://jobs.lever.co/acme/abc", "lever")}},
+		{"(see https://jobs.ashbyhq.com/acme)", []ContactPoint{ats("https://jobs.ashbyhq.com/acme", "ashby")}},
+		{"https://apply.workable.com/acme/j/1", []ContactPoint{ats("https://apply.workable.com/acme/j/1", "workable")}},
+		{"www.acme.com/careers or jobs@acme.com", []ContactPoint{
+			{Kind: ContactURL, Value: "https://www.acme.com/careers", Valid: true},
+			email("jobs@acme.com"),
+		}},
+		// " at " without a mailbox name or an obfuscated dot is just prose
+		{"we meet at noon.", nil},
+		{"ping bob at acme.com", nil},
+		{"Our team at Acme.io builds rockets", nil},
+		{"We're hiring at acme.com!", nil},
+		// bare domains count as links when they have a path
+		{"apply at acme.com/jobs.", []ContactPoint{{Kind: ContactURL, Value: "https://acme.com/jobs", Valid: true}}},
+		{"Node.js/React, 5+ years", nil},
+		{"jobs@acme", nil},
+	}
+	for _, tt := range tests {
+		if got := ExtractContacts(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExtractContacts(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPrimaryContact(t *testing.T) {
+	contacts := ExtractContacts("https://acme.example/about https://jobs.lever.co/acme jobs [at] acme (dot) example")
+	c, ok := PrimaryContact(contacts)
+	if !ok || c.Kind != ContactEmail {
+		t.Errorf("PrimaryContact = %+v, want the email over the links", c)
+	}
+	if _, ok := PrimaryContact(nil); ok {
+		t.Error("PrimaryContact found a contact in nothing")
+	}
+}
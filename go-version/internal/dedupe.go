@@ -0,0 +1,187 @@
+package internal_linkedin_scraper
+
+import (
+	"hash/fnv"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MergeDuplicates makes Run send only one of each group of duplicate
+// postings to the model, and link the listings it gets to every posting in
+// the group.
+var MergeDuplicates = true
+
+const (
+	// shingleSize is the number of words in a shingle, and minhashSize the
+	// number of hash functions in a MinHash signature
+	shingleSize = 4
+	minhashSize = 128
+
+	// duplicateSimilarity is the estimated Jaccard similarity of shingles
+	// above which two postings are duplicates whoever posted them, and
+	// sameCompanySimilarity the lower bar for two postings by one company
+	// for the same role, which are usually the same text edited and posted
+	// again
+	duplicateSimilarity   = 0.8
+	sameCompanySimilarity = 0.5
+)
+
+var (
+	dedupeWordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+	// minhashSeeds derive the minhashSize hash functions from one hash
+	minhashSeeds = func() [minhashSize]uint64 {
+		var seeds [minhashSize]uint64
+		x := uint64(0x9e3779b97f4a7c15)
+		for i := range seeds {
+			x = splitmix64(x)
+			seeds[i] = x
+		}
+		return seeds
+	}()
+)
+
+// signature is the MinHash signature of a text's shingles.
+type signature [minhashSize]uint64
+
+// minhash computes the MinHash signature of text, over its lowercased words
+// taken shingleSize at a time. ok is false if text has no words.
+func minhash(text string) (sig signature, ok bool) {
+	words := dedupeWordPattern.FindAllString(strings.ToLower(text), -1)
+	if len(words) == 0 {
+		return sig, false
+	}
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for start := 0; start == 0 || start+shingleSize <= len(words); start++ {
+		end := min(start+shingleSize, len(words))
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[start:end], " ")))
+		shingle := h.Sum64()
+		for i, seed := range minhashSeeds {
+			if v := splitmix64(shingle ^ seed); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig, true
+}
+
+// similarity estimates the Jaccard similarity of the shingles behind two
+// signatures.
+func (s signature) similarity(other signature) float64 {
+	same := 0
+	for i := range s {
+		if s[i] == other[i] {
+			same++
+		}
+	}
+	return float64(same) / minhashSize
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// postingHeader is the normalized company name a posting leads with and
+// the rest of its header line, role first. HN postings start with a
+// "Company | Role | Location" line, though not always in that order.
+func postingHeader(text string) (company string, segments []string) {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	parts := strings.Split(line, "|")
+	if len(parts) < 2 {
+		return "", nil
+	}
+	for _, part := range parts[1:] {
+		if segment := strings.Join(dedupeWordPattern.FindAllString(strings.ToLower(part), -1), " "); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return NormalizeCompanyName(parts[0]), segments
+}
+
+// sameRole reports whether two headers can name the same role: each one's
+// role is among the other's segments, so a repost that moves the role after
+// the location still counts, while two headers with different roles don't.
+// A posting without a header could be any role.
+func sameRole(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	return slices.Contains(b, a[0]) && slices.Contains(a, b[0])
+}
+
+// dedupePostings merges duplicate postings within each thread: the same
+// text posted twice, by the company or by recruiters, or a company posting
+// again with its text edited. Postings whose headers name different roles
+// are never merged, however much text they share, since companies often
+// repeat the same paragraph about themselves in every posting. threadOf
+// holds the thread of each posting.
+// Each group of duplicates is kept as its longest posting, with the others
+// in its Duplicates. It returns the postings kept and their threads.
+func dedupePostings(postings []Posting, threadOf []int) ([]Posting, []int) {
+	sigs := make([]signature, len(postings))
+	hashed := make([]bool, len(postings))
+	companies := make([]string, len(postings))
+	roles := make([][]string, len(postings))
+	for i, p := range postings {
+		sigs[i], hashed[i] = minhash(p.Text)
+		companies[i], roles[i] = postingHeader(p.Text)
+	}
+
+	// group[j] is the first posting of j's group
+	group := make([]int, len(postings))
+	for i := range group {
+		group[i] = i
+	}
+	for i := range postings {
+		if !hashed[i] || group[i] != i {
+			continue
+		}
+		for j := i + 1; j < len(postings); j++ {
+			if !hashed[j] || group[j] != j || threadOf[i] != threadOf[j] {
+				continue
+			}
+			if !sameRole(roles[i], roles[j]) {
+				continue
+			}
+			bar := duplicateSimilarity
+			if companies[i] != "" && companies[i] == companies[j] && (len(roles[i]) == 0) == (len(roles[j]) == 0) {
+				bar = sameCompanySimilarity
+			}
+			if sigs[i].similarity(sigs[j]) >= bar {
+				group[j] = i
+			}
+		}
+	}
+
+	// each group is kept as its longest posting, in the place of its first
+	keep := make(map[int]int)
+	for i := range postings {
+		if k, ok := keep[group[i]]; !ok || len(postings[i].Text) > len(postings[k].Text) {
+			keep[group[i]] = i
+		}
+	}
+	var merged []Posting
+	var mergedThreadOf []int
+	for i := range postings {
+		if group[i] != i {
+			continue
+		}
+		p := postings[keep[i]]
+		for j := i; j < len(postings); j++ {
+			if group[j] == i && j != keep[i] {
+				p.Duplicates = append(p.Duplicates, postings[j].Comment)
+			}
+		}
+		merged = append(merged, p)
+		mergedThreadOf = append(mergedThreadOf, threadOf[i])
+	}
+	return merged, mergedThreadOf
+}
@@ -0,0 +1,115 @@
+package internal_linkedin_scraper
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMinhashSimilarity(t *testing.T) {
+	text := "Acme Payments | Senior Go Engineer | Remote (US) | $180k-$220k\nWe build payment rails for small businesses. Our stack is Go, Postgres and React, deployed on AWS. Email jobs@acme.example with a short note about something you built."
+	a, _ := minhash(text)
+	b, _ := minhash(text)
+	if got := a.similarity(b); got != 1 {
+		t.Errorf("identical texts: similarity %.2f, want 1", got)
+	}
+
+	edited, _ := minhash(strings.Replace(text, "$180k-$220k", "$190k-$230k", 1))
+	if got := a.similarity(edited); got < sameCompanySimilarity {
+		t.Errorf("lightly edited text: similarity %.2f, want at least %.2f", got, sameCompanySimilarity)
+	}
+
+	other, _ := minhash("Globex | Frontend Engineer | Berlin, onsite\nGlobex makes logistics software for ports. We are looking for a frontend engineer who knows TypeScript and Vue. Apply at globex.example/careers.")
+	if got := a.similarity(other); got > 0.2 {
+		t.Errorf("unrelated texts: similarity %.2f, want close to 0", got)
+	}
+
+	if _, ok := minhash(" -- "); ok {
+		t.Error("text without words got a signature")
+	}
+}
+
+func TestDedupePostings(t *testing.T) {
+	acme := "Acme Payments | Senior Go Engineer | Remote (US) | $180k-$220k\nWe build payment rails for small businesses. Our stack is Go, Postgres and React, deployed on AWS. Email jobs@acme.example with a short note about something you built."
+	recruiter := "Stealth fintech | Staff Backend Engineer | NYC or remote | $250k + equity\nMy client is a well funded fintech building a ledger for banks. You will own the core services in Go and Kafka. Message me at recruiter@staffing.example for details."
+	posting := func(id, author, text string) Posting {
+		return Posting{Comment: Comment{ID: id, Author: author, Text: text}}
+	}
+
+	postings := []Posting{
+		posting("1", "acme", acme),
+		posting("2", "recruiter1", recruiter),
+		posting("3", "globex", "Globex | Frontend Engineer | Berlin, onsite\nGlobex makes logistics software for ports. We are looking for a frontend engineer who knows TypeScript and Vue. Apply at globex.example/careers."),
+		// the same company again, its text edited and extended
+		posting("4", "acme_hr", strings.Replace(acme, "$180k-$220k", "$190k-$230k", 1)+" We also sponsor visas."),
+		// a second recruiter with the same text
+		posting("5", "recruiter2", strings.Replace(recruiter, "recruiter@", "talent@", 1)),
+		// the same Acme posting in next month's thread is not a duplicate
+		posting("6", "acme", acme),
+		posting("7", "", "  "),
+		posting("8", "", "  "),
+	}
+	threadOf := []int{0, 0, 0, 0, 0, 1, 0, 0}
+
+	merged, mergedThreadOf := dedupePostings(postings, threadOf)
+	var ids []string
+	for _, p := range merged {
+		ids = append(ids, p.ID)
+	}
+	// each group keeps its longest posting, in place of its first
+	if want := []string{"4", "2", "3", "6", "7", "8"}; !slices.Equal(ids, want) {
+		t.Fatalf("kept postings %v, want %v", ids, want)
+	}
+	if want := []int{0, 0, 0, 1, 0, 0}; !slices.Equal(mergedThreadOf, want) {
+		t.Errorf("threads %v, want %v", mergedThreadOf, want)
+	}
+	if got := merged[0].SourceIDs(); !slices.Equal(got, []string{"4", "1"}) {
+		t.Errorf("Acme source ids %v, want [4 1]", got)
+	}
+	if got := merged[1].SourceIDs(); !slices.Equal(got, []string{"2", "5"}) {
+		t.Errorf("recruiter source ids %v, want [2 5]", got)
+	}
+	if got := merged[2].SourceIDs(); got != nil {
+		t.Errorf("Globex source ids %v, want none", got)
+	}
+}
+
+func TestDedupePostingsKeepsRolesApart(t *testing.T) {
+	about := "\nAcme builds payment rails for small businesses across North America. We are a team of forty, profitable, and growing fast. Our benefits include full health coverage, a learning budget and four weeks of paid leave. Email jobs@acme.example with a short note about something you built."
+	postings := []Posting{
+		{Comment: Comment{ID: "1", Text: "Acme | Senior Go Engineer | Remote | $180k-$220k" + about}},
+		{Comment: Comment{ID: "2", Text: "Acme | Product Designer | Toronto | $120k-$140k" + about}},
+	}
+	if a, b := postings[0], postings[1]; similarityOf(a.Text, b.Text) < sameCompanySimilarity {
+		t.Fatalf("the shared paragraph should make these look alike, similarity %.2f", similarityOf(a.Text, b.Text))
+	}
+
+	merged, _ := dedupePostings(postings, []int{0, 0})
+	if len(merged) != 2 {
+		t.Errorf("kept %d postings, want both roles", len(merged))
+	}
+
+	// a repost with the header reordered is still the same role, but a
+	// different role with the location first isn't
+	postings = append(postings,
+		Posting{Comment: Comment{ID: "3", Text: "Acme | Remote | Senior Go Engineer | $180k-$220k" + about}},
+		Posting{Comment: Comment{ID: "4", Text: "Acme | Toronto | Data Engineer" + about}},
+	)
+	merged, _ = dedupePostings(postings, []int{0, 0, 0, 0})
+	var ids []string
+	for _, p := range merged {
+		ids = append(ids, p.ID)
+	}
+	if want := []string{"1", "2", "4"}; !slices.Equal(ids, want) {
+		t.Fatalf("kept %v, want %v", ids, want)
+	}
+	if got := merged[0].SourceIDs(); !slices.Equal(got, []string{"1", "3"}) {
+		t.Errorf("Go Engineer source ids %v, want the reordered repost merged in", got)
+	}
+}
+
+func similarityOf(a, b string) float64 {
+	sa, _ := minhash(a)
+	sb, _ := minhash(b)
+	return sa.similarity(sb)
+}
@@ -0,0 +1,375 @@
+package internal_linkedin_scraper
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// GoldComment is a hand-labeled comment from the evaluation set, with the
+// listings a perfect extractor would return for it.
+type GoldComment struct {
+	ID       string    `json:"id"`
+	Text     string    `json:"text"`
+	Listings []Listing `json:"listings"`
+}
+
+// EvalComment pairs a gold comment's labels with what the extractor returned.
+type EvalComment struct {
+	ID        string    `json:"id"`
+	Gold      []Listing `json:"gold"`
+	Predicted []Listing `json:"predicted"`
+}
+
+// FieldScore counts how one Listing field fared across the gold set. A
+// non-empty prediction is a true positive when it matches the label and a
+// false positive otherwise; a label the extractor missed or got wrong is a
+// false negative. Exact counts pairs where both agree, empty included.
+type FieldScore struct {
+	TruePositives  int `json:"truePositives"`
+	FalsePositives int `json:"falsePositives"`
+	FalseNegatives int `json:"falseNegatives"`
+	Exact          int `json:"exact"`
+	Total          int `json:"total"`
+}
+
+func (s FieldScore) Precision() float64 {
+	if s.TruePositives+s.FalsePositives == 0 {
+		return 0
+	}
+	return float64(s.TruePositives) / float64(s.TruePositives+s.FalsePositives)
+}
+
+func (s FieldScore) Recall() float64 {
+	if s.TruePositives+s.FalseNegatives == 0 {
+		return 0
+	}
+	return float64(s.TruePositives) / float64(s.TruePositives+s.FalseNegatives)
+}
+
+func (s FieldScore) ExactMatch() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Exact) / float64(s.Total)
+}
+
+// EvalResult is a scored run over the gold set. It is saved as JSON so two
+// runs, e.g. of different models, can be compared with DiffEval.
+type EvalResult struct {
+	Model    string                `json:"model"`
+	Gold     string                `json:"gold"`
+	Fields   map[string]FieldScore `json:"fields"`
+	Comments []EvalComment         `json:"comments"`
+	Report   RunReport             `json:"report"`
+}
+
+// evalField is a Listing field scored by the evaluation, with the
+// comparison used for it. Values are only compared when both are non-empty.
+type evalField struct {
+	name  string
+	value func(Listing) string
+	equal func(a, b string) bool
+}
+
+var evalFields = []evalField{
+	{"company", func(l Listing) string { return l.Company }, sameText},
+	{"title", func(l Listing) string { return l.Title }, sameText},
+	{"location", func(l Listing) string { return l.Location }, sameLocation},
+	{"pay", func(l Listing) string { return l.Pay }, samePay},
+	{"seniority", func(l Listing) string { return l.Seniority }, sameLevel},
+	{"contact", func(l Listing) string { return l.Contact }, sameContacts},
+}
+
+var nonWordPattern = regexp.MustCompile(`[^\p{L}\p{N}+#]+`)
+
+// normalizeText lowercases s and reduces punctuation and spacing, so
+// "Acme, Inc." and "acme inc" compare equal.
+func normalizeText(s string) string {
+	return strings.TrimSpace(nonWordPattern.ReplaceAllString(strings.ToLower(s), " "))
+}
+
+func sameText(a, b string) bool {
+	return normalizeText(a) == normalizeText(b)
+}
+
+func samePay(a, b string) bool {
+	pa, pb := ParsePay(a), ParsePay(b)
+	if pa.IsZero() || pb.IsZero() {
+		return sameText(a, b)
+	}
+	return pa.Min == pb.Min && pa.Max == pb.Max && pa.Currency == pb.Currency && pa.Period == pb.Period
+}
+
+func sameLevel(a, b string) bool {
+	return NormalizeLevel(a) == NormalizeLevel(b)
+}
+
+// sameLocation compares work policies exactly, but lets one side name fewer
+// places, as in "Berlin" and "Berlin, Germany".
+func sameLocation(a, b string) bool {
+	la, lb := ParseLocation(a), ParseLocation(b)
+	if len(la.Places)+len(la.Policies) == 0 || len(lb.Places)+len(lb.Policies) == 0 {
+		return sameText(a, b)
+	}
+	return sameSet(la.Policies, lb.Policies) && (subset(la.Places, lb.Places) || subset(lb.Places, la.Places))
+}
+
+func sameContacts(a, b string) bool {
+	ca, cb := contactValues(a), contactValues(b)
+	if len(ca) == 0 || len(cb) == 0 {
+		return sameText(a, b)
+	}
+	return sameSet(ca, cb)
+}
+
+func contactValues(s string) []string {
+	var values []string
+	for _, c := range ExtractContacts(s) {
+		values = append(values, strings.ToLower(c.Value))
+	}
+	return values
+}
+
+func subset(a, b []string) bool {
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// LoadGoldSet reads a JSONL file with one GoldComment per line.
+func LoadGoldSet(path string) ([]GoldComment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var gold []GoldComment
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var comment GoldComment
+		if err := json.Unmarshal(scanner.Bytes(), &comment); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		gold = append(gold, comment)
+	}
+	return gold, scanner.Err()
+}
+
+// Evaluate runs the extractor over every gold comment and scores the result.
+// It fails if any comment couldn't be extracted, since the scores would be
+// meaningless.
+func Evaluate(gold []GoldComment) (EvalResult, error) {
+	runStats = newRunStats()
+	budget = budgetFromEnv()
+
+	comments := make([]EvalComment, len(gold))
+	errs := make([]error, len(gold))
+	var wg sync.WaitGroup
+	for i, g := range gold {
+		wg.Add(1)
+		go func(index int, g GoldComment) {
+			defer wg.Done()
+			predicted, err := serializeListing(g.Text)
+			comments[index] = EvalComment{ID: g.ID, Gold: g.Listings, Predicted: predicted}
+			errs[index] = err
+		}(i, g)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return EvalResult{}, fmt.Errorf("failed to extract gold comments: %w", err)
+	}
+
+	return EvalResult{
+		Model:    ExtractionModel,
+		Fields:   scoreComments(comments),
+		Comments: comments,
+		Report:   runStats.Report(),
+	}, nil
+}
+
+// scoreComments pairs up gold and predicted listings within each comment and
+// scores every field over the pairs. Listings left without a partner count
+// against precision or recall for each field they fill in.
+func scoreComments(comments []EvalComment) map[string]FieldScore {
+	scores := make(map[string]FieldScore, len(evalFields))
+	for _, c := range comments {
+		for _, pair := range pairListings(c.Gold, c.Predicted) {
+			for _, field := range evalFields {
+				scores[field.name] = scoreField(scores[field.name], field, pair)
+			}
+		}
+	}
+	return scores
+}
+
+func scoreField(s FieldScore, field evalField, pair [2]*Listing) FieldScore {
+	var gold, predicted string
+	if pair[0] != nil {
+		gold = field.value(*pair[0])
+	}
+	if pair[1] != nil {
+		predicted = field.value(*pair[1])
+	}
+	hasGold, hasPredicted := normalizeText(gold) != "", normalizeText(predicted) != ""
+	match := hasGold && hasPredicted && field.equal(gold, predicted)
+
+	s.Total++
+	if match || !hasGold && !hasPredicted {
+		s.Exact++
+	}
+	switch {
+	case match:
+		s.TruePositives++
+	case hasPredicted:
+		s.FalsePositives++
+		if hasGold {
+			s.FalseNegatives++
+		}
+	case hasGold:
+		s.FalseNegatives++
+	}
+	return s
+}
+
+// pairListings matches each gold listing with the unclaimed prediction that
+// agrees with it on the most fields. Unmatched listings are paired with nil.
+func pairListings(gold, predicted []Listing) [][2]*Listing {
+	claimed := make([]bool, len(predicted))
+	var pairs [][2]*Listing
+	for i := range gold {
+		best, bestScore := -1, -1
+		for j := range predicted {
+			if claimed[j] {
+				continue
+			}
+			score := 0
+			for _, field := range evalFields {
+				a, b := field.value(gold[i]), field.value(predicted[j])
+				if normalizeText(a) != "" && normalizeText(b) != "" && field.equal(a, b) {
+					score++
+				}
+			}
+			if score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best < 0 {
+			pairs = append(pairs, [2]*Listing{&gold[i], nil})
+			continue
+		}
+		claimed[best] = true
+		pairs = append(pairs, [2]*Listing{&gold[i], &predicted[best]})
+	}
+	for j := range predicted {
+		if !claimed[j] {
+			pairs = append(pairs, [2]*Listing{nil, &predicted[j]})
+		}
+	}
+	return pairs
+}
+
+// Summary renders the per-field scores as a table.
+func (r EvalResult) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧪 Evaluation of %s over %d comments\n", r.Model, len(r.Comments))
+	fmt.Fprintf(&b, "  %-10s %9s %9s %9s\n", "field", "precision", "recall", "exact")
+	for _, field := range evalFields {
+		s := r.Fields[field.name]
+		fmt.Fprintf(&b, "  %-10s %8.1f%% %8.1f%% %8.1f%%\n", field.name, s.Precision()*100, s.Recall()*100, s.ExactMatch()*100)
+	}
+	fmt.Fprintf(&b, "  💰 %d tokens in, %d out, $%.4f\n", r.Report.PromptTokens, r.Report.CompletionTokens, r.Report.Cost)
+	return b.String()
+}
+
+// SaveEvalResult writes r to path as JSON.
+func SaveEvalResult(r EvalResult, path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadEvalResult reads a result saved by SaveEvalResult.
+func LoadEvalResult(path string) (EvalResult, error) {
+	var r EvalResult
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// DiffEval compares two evaluation runs: the change in each field's scores,
+// then every comment whose fields one run got right and the other didn't.
+func DiffEval(a, b EvalResult) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "🔀 %s → %s\n", a.Model, b.Model)
+	fmt.Fprintf(&out, "  %-10s %16s %16s %16s\n", "field", "precision", "recall", "exact")
+	for _, field := range evalFields {
+		sa, sb := a.Fields[field.name], b.Fields[field.name]
+		fmt.Fprintf(&out, "  %-10s %s %s %s\n", field.name,
+			formatDelta(sa.Precision(), sb.Precision()),
+			formatDelta(sa.Recall(), sb.Recall()),
+			formatDelta(sa.ExactMatch(), sb.ExactMatch()))
+	}
+	fmt.Fprintf(&out, "  💰 $%.4f → $%.4f\n", a.Report.Cost, b.Report.Cost)
+
+	predictedB := make(map[string]EvalComment, len(b.Comments))
+	for _, c := range b.Comments {
+		predictedB[c.ID] = c
+	}
+	for _, ca := range a.Comments {
+		cb, ok := predictedB[ca.ID]
+		if !ok {
+			continue
+		}
+		scoresA := scoreComments([]EvalComment{ca})
+		scoresB := scoreComments([]EvalComment{cb})
+		for _, field := range evalFields {
+			if scoresA[field.name].Exact == scoresB[field.name].Exact {
+				continue
+			}
+			fmt.Fprintf(&out, "  %s %-10s %q → %q\n", ca.ID, field.name, fieldValues(ca.Predicted, field), fieldValues(cb.Predicted, field))
+		}
+	}
+	return out.String()
+}
+
+func formatDelta(a, b float64) string {
+	return fmt.Sprintf("%5.1f%% → %5.1f%%", a*100, b*100)
+}
+
+func fieldValues(listings []Listing, field evalField) string {
+	values := make([]string, 0, len(listings))
+	for _, l := range listings {
+		values = append(values, field.value(l))
+	}
+	return strings.Join(values, " | ")
+}
@@ -0,0 +1,56 @@
+package internal_linkedin_scraper
+
+import "testing"
+
+func TestScoreComments(t *testing.T) {
+	comments := []EvalComment{
+		{
+			ID: "1",
+			Gold: []Listing{
+				{Company: "Globex", Title: "Frontend Engineer", Location: "Berlin, Germany (Hybrid)", Pay: "€70k-€85k", Contact: "hr@globex.example"},
+				{Company: "Globex", Title: "Staff Frontend Engineer", Location: "Berlin, Germany (Hybrid)", Pay: "€95k-€110k", Seniority: "staff", Contact: "hr@globex.example"},
+			},
+			// listed out of order, with equivalent spellings
+			Predicted: []Listing{
+				{Company: "Globex.", Title: "staff frontend engineer", Location: "Hybrid - Berlin", Pay: "€95,000 - €110,000", Seniority: "Staff", Contact: "HR@globex.example"},
+				{Company: "Globex", Title: "Frontend Engineer", Location: "Berlin", Pay: "€70k-€85k", Seniority: "senior", Contact: "hr@globex.example"},
+			},
+		},
+		{
+			ID:        "2",
+			Gold:      []Listing{{Company: "Initech", Title: "Embedded C++ Developer", Contact: "https://initech.example/careers"}},
+			Predicted: nil,
+		},
+		{
+			ID:        "3",
+			Gold:      nil,
+			Predicted: []Listing{{Company: "Hooli", Title: "Reply"}},
+		},
+	}
+
+	scores := scoreComments(comments)
+	tests := []struct {
+		field      string
+		tp, fp, fn int
+		exact      int
+	}{
+		// Globex twice, Initech missed, Hooli made up
+		{"company", 2, 1, 1, 2},
+		{"title", 2, 1, 1, 2},
+		// "Berlin" drops the hybrid policy
+		{"location", 1, 1, 1, 3},
+		{"pay", 2, 0, 0, 4},
+		// a seniority was invented for the first Globex role
+		{"seniority", 1, 1, 0, 3},
+		{"contact", 2, 0, 1, 3},
+	}
+	for _, tt := range tests {
+		s := scores[tt.field]
+		if s.TruePositives != tt.tp || s.FalsePositives != tt.fp || s.FalseNegatives != tt.fn || s.Exact != tt.exact {
+			t.Errorf("%s = %+v, want tp=%d fp=%d fn=%d exact=%d", tt.field, s, tt.tp, tt.fp, tt.fn, tt.exact)
+		}
+		if s.Total != 4 {
+			t.Errorf("%s total = %d, want 4 pairs", tt.field, s.Total)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package internal_linkedin_scraper
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const csvFileName = "listings.csv"
+
+// csvColumns are the compiled columns of the CSV export. ExtraFields follow
+// them, one column each.
+var csvColumns = []struct {
+	name  string
+	value func(Listing) string
+}{
+	{"commentId", func(l Listing) string { return l.CommentID }},
+	{"company", func(l Listing) string { return l.Company }},
+	{"title", func(l Listing) string { return l.Title }},
+	{"location", func(l Listing) string { return l.Location }},
+	{"pay", func(l Listing) string { return l.Pay }},
+	{"technologies", func(l Listing) string { return l.Technologies }},
+	{"seniority", func(l Listing) string { return l.Seniority }},
+	{"employmentType", func(l Listing) string { return l.EmploymentType }},
+	{"contact", func(l Listing) string { return l.Contact }},
+	{"description", func(l Listing) string { return l.Description }},
+	{"status", func(l Listing) string { return l.Status }},
+}
+
+// WriteCSV writes listings as CSV with a header row. Closed listings are
+// left out unless IncludeClosed is set.
+func WriteCSV(w io.Writer, listings []Listing) error {
+	out := csv.NewWriter(w)
+	header := make([]string, 0, len(csvColumns)+len(ExtraFields))
+	for _, column := range csvColumns {
+		header = append(header, column.name)
+	}
+	for _, field := range ExtraFields {
+		header = append(header, field.Name)
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	for _, l := range exportable(listings) {
+		record := make([]string, 0, len(header))
+		for _, column := range csvColumns {
+			record = append(record, column.value(l))
+		}
+		for _, field := range ExtraFields {
+			value, ok := l.Extra[field.Name]
+			if !ok || value == nil {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, fmt.Sprint(value))
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeCSV saves listings as CSV in dir.
+func writeCSV(dir string, listings []Listing) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, csvFileName)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if err := WriteCSV(file, listings); err != nil {
+		return "", fmt.Errorf("failed to write CSV export: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,86 @@
+package internal_linkedin_scraper
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// titlePattern spots the role segment of an HN-style "Company | Role | ..." header
+	titlePattern = regexp.MustCompile(`(?i)\b(?:engineer|developer|programmer|designer|scientist|architect|manager|lead|director|analyst|researcher|devops|sre|intern|founding|head of|cto|full[- ]?stack|front[- ]?end|back[- ]?end)\b`)
+
+	// keywordPatterns match each of includeKeywords as a whole word
+	keywordPatterns = func() []*regexp.Regexp {
+		patterns := make([]*regexp.Regexp, len(includeKeywords))
+		for i, keyword := range includeKeywords {
+			patterns[i] = regexp.MustCompile(`\b` + regexp.QuoteMeta(keyword) + `\b`)
+		}
+		return patterns
+	}()
+)
+
+// fallbackListings builds a Listing from comment without the model, using
+// the "Company | Role | Location | Pay" header most HN posts follow. It is
+// the last resort when extraction fails and is marked Degraded.
+func fallbackListings(comment string) []Listing {
+	header, _, _ := strings.Cut(strings.TrimSpace(comment), "\n")
+	if !strings.Contains(header, "|") {
+		return nil
+	}
+
+	segments := strings.Split(header, "|")
+	l := Listing{
+		Company:     strings.TrimSpace(segments[0]),
+		Description: comment,
+		Degraded:    true,
+	}
+	for _, segment := range segments[1:] {
+		segment = strings.TrimSpace(segment)
+		pay := ParsePay(segment)
+		switch {
+		case segment == "":
+		case l.Pay == "" && !pay.IsZero() && pay.Currency != "":
+			l.Pay = segment
+		case l.Location == "" && isLocationSegment(segment):
+			l.Location = segment
+		case l.Title == "" && titlePattern.MatchString(segment):
+			l.Title = segment
+		}
+	}
+
+	if c, ok := PrimaryContact(ExtractContacts(comment)); ok {
+		l.Contact = c.Value
+	}
+
+	var technologies []string
+	lower := strings.ToLower(comment)
+	for i, pattern := range keywordPatterns {
+		if pattern.MatchString(lower) {
+			technologies = appendUnique(technologies, includeKeywords[i])
+		}
+	}
+	l.Technologies = strings.Join(technologies, ", ")
+
+	if l.Company == "" && l.Contact == "" {
+		return nil
+	}
+	l.enrich()
+	return []Listing{l}
+}
+
+func isLocationSegment(segment string) bool {
+	w := ParseLocation(segment)
+	if len(w.Policies) > 0 {
+		return true
+	}
+	for _, place := range append(w.Places, w.Regions...) {
+		lower := strings.ToLower(place)
+		if _, ok := cityByName(lower); ok {
+			return true
+		}
+		if _, ok := regionAliases[lower]; ok {
+			return true
+		}
+	}
+	return false
+}
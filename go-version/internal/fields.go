@@ -0,0 +1,112 @@
+package internal_linkedin_scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sashabaranov/go-openai/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// ExtraField is a user-defined field the model extracts from every post on
+// top of the compiled ones, like "visa sponsorship" or "team size". Values
+// land in Listing.Extra under Name and are exported as extra CSV columns.
+type ExtraField struct {
+	Name        string   `json:"name" yaml:"name"`
+	Type        string   `json:"type" yaml:"type"`
+	Description string   `json:"description" yaml:"description"`
+	Enum        []string `json:"enum,omitempty" yaml:"enum,omitempty"`
+}
+
+// ExtraFields are the user-defined fields added to the extraction schema,
+// in the order they appear as CSV columns. They are set by LoadExtraFields.
+var ExtraFields []ExtraField
+
+// extraFieldTypes maps the types allowed in the config to schema types.
+var extraFieldTypes = map[string]jsonschema.DataType{
+	"string":  jsonschema.String,
+	"number":  jsonschema.Number,
+	"integer": jsonschema.Integer,
+	"boolean": jsonschema.Boolean,
+}
+
+var extraFieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadExtraFields reads the extra fields from a YAML or JSON file, picked by
+// its extension. A missing file leaves the schema as compiled.
+func LoadExtraFields(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var config struct {
+		Fields []ExtraField `json:"fields" yaml:"fields"`
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &config)
+	default:
+		err = json.Unmarshal(data, &config)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, field := range config.Fields {
+		if !extraFieldName.MatchString(field.Name) {
+			return fmt.Errorf("%s: field %d has invalid name %q", path, i+1, field.Name)
+		}
+		if seen[field.Name] {
+			return fmt.Errorf("%s: field %q is declared twice", path, field.Name)
+		}
+		seen[field.Name] = true
+		if field.Type == "" {
+			config.Fields[i].Type = "string"
+		} else if _, ok := extraFieldTypes[field.Type]; !ok {
+			return fmt.Errorf("%s: field %q has unsupported type %q", path, field.Name, field.Type)
+		}
+		if len(field.Enum) > 0 && config.Fields[i].Type != "string" {
+			return fmt.Errorf("%s: field %q has an enum but isn't a string", path, field.Name)
+		}
+	}
+	ExtraFields = config.Fields
+	return nil
+}
+
+// extraFieldsSchema is the "extra" object added to the Post schema. Strict
+// mode needs every property to be required, so empty strings, zero and false
+// stand in for values the post doesn't mention.
+func extraFieldsSchema(fields []ExtraField) jsonschema.Definition {
+	schema := jsonschema.Definition{
+		Type:                 jsonschema.Object,
+		Description:          "Additional details requested about the post",
+		Properties:           make(map[string]jsonschema.Definition, len(fields)),
+		AdditionalProperties: false,
+	}
+	for _, field := range fields {
+		property := jsonschema.Definition{
+			Type:        extraFieldTypes[field.Type],
+			Description: field.Description,
+		}
+		if len(field.Enum) > 0 {
+			// like the compiled enums, allow "" for posts that don't say
+			property.Enum = field.Enum
+			if !slices.Contains(property.Enum, "") {
+				property.Enum = append([]string{""}, property.Enum...)
+			}
+		}
+		schema.Properties[field.Name] = property
+		schema.Required = append(schema.Required, field.Name)
+	}
+	return schema
+}
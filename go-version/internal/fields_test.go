@@ -0,0 +1,97 @@
+package internal_linkedin_scraper
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func useExtraFields(t *testing.T, config string) {
+	t.Helper()
+	prev := ExtraFields
+	t.Cleanup(func() { ExtraFields = prev })
+
+	path := filepath.Join(t.TempDir(), "fields.yaml")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadExtraFields(path); err != nil {
+		t.Fatalf("LoadExtraFields: %v", err)
+	}
+}
+
+func TestExtraFieldsExtendSchemaAndExport(t *testing.T) {
+	useExtraFields(t, `
+fields:
+  - name: visaSponsorship
+    enum: ["yes", "no"]
+    description: Whether the company sponsors work visas
+  - name: teamSize
+    type: integer
+    description: Size of the team
+`)
+
+	schema := GetListingSchema()
+	extra, ok := schema.Properties["extra"]
+	if !ok {
+		t.Fatal("schema has no extra property")
+	}
+	if len(extra.Required) != 2 || extra.Properties["teamSize"].Type != "integer" {
+		t.Errorf("extra schema = %+v, want both fields required", extra)
+	}
+	if enum := extra.Properties["visaSponsorship"].Enum; len(enum) != 3 || enum[0] != "" {
+		t.Errorf("visaSponsorship enum = %q, want empty allowed", enum)
+	}
+
+	var post Post
+	content := `{"company":"Acme","location":"Remote","description":"Acme | Go | Remote","contact":"jobs@acme.example",
+		"positions":[{"title":"Go Engineer","location":"","pay":"","technologies":"Go","seniority":"","employmentType":""}],
+		"extra":{"visaSponsorship":"yes","teamSize":6}}`
+	if err := schema.Unmarshal(content, &post); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if err := schema.Unmarshal(`{"company":"Acme","location":"","description":"","contact":"","positions":[]}`, &Post{}); err == nil {
+		t.Error("a post without the extra fields passed validation")
+	}
+
+	listings := post.Listings()
+	if got := listings[0].Extra["visaSponsorship"]; got != "yes" {
+		t.Errorf("listing extra = %+v", listings[0].Extra)
+	}
+
+	var b bytes.Buffer
+	if err := WriteCSV(&b, listings); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, row := rows[0], rows[1]
+	if header[len(header)-2] != "visaSponsorship" || header[len(header)-1] != "teamSize" {
+		t.Errorf("header = %q, want the extra fields last", header)
+	}
+	if row[len(row)-2] != "yes" || row[len(row)-1] != "6" {
+		t.Errorf("row = %q, want the extra values last", row)
+	}
+}
+
+func TestLoadExtraFieldsRejectsBadConfig(t *testing.T) {
+	prev := ExtraFields
+	t.Cleanup(func() { ExtraFields = prev })
+
+	for name, config := range map[string]string{
+		"unknown type":  `{"fields":[{"name":"teamSize","type":"date"}]}`,
+		"bad name":      `{"fields":[{"name":"team size"}]}`,
+		"duplicate":     `{"fields":[{"name":"a"},{"name":"a"}]}`,
+		"enum on a int": `{"fields":[{"name":"a","type":"integer","enum":["1"]}]}`,
+	} {
+		path := filepath.Join(t.TempDir(), "fields.json")
+		os.WriteFile(path, []byte(config), 0644)
+		if err := LoadExtraFields(path); err == nil {
+			t.Errorf("%s: LoadExtraFields accepted %s", name, config)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package internal_linkedin_scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// FixtureMode selects whether HTTP traffic is recorded to or replayed from
+// fixture files.
+type FixtureMode int
+
+const (
+	FixturesRecord FixtureMode = iota + 1
+	FixturesReplay
+)
+
+// httpClient carries every request the scraper makes, HN pages and API
+// calls alike, so UseFixtures can capture or stand in for all of them.
+var httpClient = &http.Client{}
+
+// strippedFixtureHeaders are response headers not worth keeping in a fixture.
+var strippedFixtureHeaders = []string{"Set-Cookie", "Openai-Organization", "Openai-Project"}
+
+// fixture is the metadata of a recorded response. The body is stored next
+// to it, unchanged, so HTML and JSON fixtures stay readable.
+type fixture struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+}
+
+// fixtureTransport records each response to dir, or answers each request
+// from dir without touching the network. Requests are matched on method,
+// URL and body, so recorded API calls only replay for identical prompts.
+type fixtureTransport struct {
+	dir      string
+	mode     FixtureMode
+	upstream http.RoundTripper
+}
+
+// UseFixtures routes all HTTP traffic through fixtures in dir: recording a
+// real run into it, or replaying one offline. The returned func puts the
+// previous transport back.
+func UseFixtures(dir string, mode FixtureMode) (restore func()) {
+	prev := httpClient.Transport
+	httpClient.Transport = &fixtureTransport{dir: dir, mode: mode, upstream: http.DefaultTransport}
+	return func() { httpClient.Transport = prev }
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	name := fixtureName(req, body)
+
+	if t.mode == FixturesReplay {
+		return t.replay(req, name)
+	}
+	res, err := t.upstream.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	return t.record(res, name)
+}
+
+// fixtureName identifies a request by hashing its method, URL and body.
+func fixtureName(req *http.Request, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL)
+	h.Write(body)
+	return fmt.Sprintf("%s-%s-%s", req.Method, req.URL.Hostname(), hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+func (t *fixtureTransport) record(res *http.Response, name string) (*http.Response, error) {
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := res.Header.Clone()
+	for _, key := range strippedFixtureHeaders {
+		header.Del(key)
+	}
+	meta, err := json.MarshalIndent(fixture{
+		Method: res.Request.Method,
+		URL:    res.Request.URL.String(),
+		Status: res.StatusCode,
+		Header: header,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, name+".json"), meta, 0644); err != nil {
+		return nil, fmt.Errorf("failed to record fixture: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, name+".body"), body, 0644); err != nil {
+		return nil, fmt.Errorf("failed to record fixture: %w", err)
+	}
+	return res, nil
+}
+
+func (t *fixtureTransport) replay(req *http.Request, name string) (*http.Response, error) {
+	data, err := os.ReadFile(filepath.Join(t.dir, name+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no fixture %s for %s %s; record one with --record-fixtures", name, req.Method, req.URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", name, err)
+	}
+	body, err := os.ReadFile(filepath.Join(t.dir, name+".body"))
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,195 @@
+package internal_linkedin_scraper
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// groundingThreshold is the confidence below which a field is treated as
+// made up by the model.
+const groundingThreshold = 0.5
+
+// BlankUngroundedFields controls whether fields that fail the grounding check
+// are cleared. When false they are only listed in Listing.Ungrounded.
+var BlankUngroundedFields = true
+
+var (
+	groundingTokenPattern = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}+#.\-]*`)
+	techSplitPattern      = regexp.MustCompile(`\s*(?:,|/|;|\||\band\b|&)\s*`)
+
+	// groundingStopwords don't count towards or against a field's support
+	groundingStopwords = map[string]bool{
+		"the": true, "a": true, "an": true, "and": true, "or": true, "of": true, "for": true, "to": true,
+		"in": true, "at": true, "inc": true, "inc.": true, "llc": true, "ltd": true, "co": true, "corp": true,
+		"gmbh": true, "per": true, "year": true, "hour": true, "plus": true, "+": true,
+	}
+)
+
+// ground checks the fields of l that the model is most prone to invent
+// against the comment it was extracted from, records a confidence for each
+// and clears (or flags) the ones the source doesn't support.
+func ground(l *Listing, source string) {
+	source = strings.ToLower(html.UnescapeString(source))
+	sourceTokens := tokenSet(source)
+
+	checks := []struct {
+		field string
+		value *string
+		score func(string) float64
+	}{
+		{"company", &l.Company, func(v string) float64 { return textSupport(v, source, sourceTokens) }},
+		{"contact", &l.Contact, func(v string) float64 { return contactSupport(v, source, sourceTokens) }},
+		{"pay", &l.Pay, func(v string) float64 { return paySupport(v, source) }},
+		{"technologies", &l.Technologies, func(v string) float64 { return technologySupport(v, source, sourceTokens) }},
+	}
+
+	l.Confidence = make(map[string]float64, len(checks))
+	l.Ungrounded = nil
+	for _, check := range checks {
+		if strings.TrimSpace(*check.value) == "" {
+			continue
+		}
+		confidence := check.score(*check.value)
+		l.Confidence[check.field] = confidence
+		grounded := confidence >= groundingThreshold
+		runStats.recordGrounding(check.field, grounded)
+		if grounded {
+			continue
+		}
+		l.Ungrounded = append(l.Ungrounded, check.field)
+		if BlankUngroundedFields {
+			*check.value = ""
+		}
+	}
+	// derived fields follow the raw ones they were parsed from
+	if len(l.Ungrounded) > 0 && BlankUngroundedFields {
+		l.enrich()
+	}
+}
+
+// textSupport scores value by the share of its words found in the source,
+// or 1 when it appears verbatim.
+func textSupport(value, source string, sourceTokens map[string]bool) float64 {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if strings.Contains(source, value) {
+		return 1
+	}
+	tokens := groundingTokenPattern.FindAllString(value, -1)
+	found, total := 0, 0
+	for _, token := range tokens {
+		token = strings.TrimRight(token, ".-")
+		if groundingStopwords[token] {
+			continue
+		}
+		total++
+		if sourceTokens[token] {
+			found++
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(found) / float64(total)
+}
+
+// contactSupport checks every email or link in value against the contacts
+// that can be decoded from the source, so de-obfuscated addresses count.
+func contactSupport(value, source string, sourceTokens map[string]bool) float64 {
+	claimed := ExtractContacts(value)
+	if len(claimed) == 0 {
+		return textSupport(value, source, sourceTokens)
+	}
+	known := make(map[string]bool)
+	for _, c := range ExtractContacts(source) {
+		known[strings.ToLower(c.Value)] = true
+	}
+	found := 0
+	for _, c := range claimed {
+		if known[strings.ToLower(c.Value)] || strings.Contains(source, strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(c.Value, "https://"), "http://"))) {
+			found++
+		}
+	}
+	return float64(found) / float64(len(claimed))
+}
+
+// paySupport checks that every figure in value appears in the source,
+// allowing for "150k" being restated as "150,000" and similar.
+func paySupport(value, source string) float64 {
+	claimed := moneyFigures(strings.ToLower(value))
+	if len(claimed) == 0 {
+		return textSupport(value, source, tokenSet(source))
+	}
+	known := make(map[float64]bool)
+	for _, f := range moneyFigures(source) {
+		known[f] = true
+		// the "120" in "120-150k" is really 120,000
+		if f < 1000 {
+			known[f*1000] = true
+		}
+	}
+	found := 0
+	for _, f := range claimed {
+		if known[f] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(claimed))
+}
+
+// technologySupport scores a comma-separated technology list by the share of
+// entries mentioned in the source, ignoring ".js"/"js" suffixes.
+func technologySupport(value, source string, sourceTokens map[string]bool) float64 {
+	found, total := 0, 0
+	for _, tech := range techSplitPattern.Split(strings.ToLower(value), -1) {
+		tech = strings.TrimSpace(tech)
+		if tech == "" {
+			continue
+		}
+		total++
+		base := strings.TrimSuffix(strings.TrimSuffix(tech, ".js"), "js")
+		if strings.Contains(source, tech) || (base != "" && sourceTokens[base]) || textSupport(tech, source, sourceTokens) >= 1 {
+			found++
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(found) / float64(total)
+}
+
+// moneyFigures returns every figure in lower scaled by its own k/m suffix.
+func moneyFigures(lower string) []float64 {
+	var figures []float64
+	for _, m := range amountPattern.FindAllStringSubmatchIndex(lower, -1) {
+		value, ok := parseNumber(lower[m[2]:m[3]])
+		if !ok {
+			continue
+		}
+		if m[4] >= 0 {
+			switch lower[m[4]:m[5]] {
+			case "k":
+				value *= 1000
+			case "m":
+				value *= 1000000
+			}
+		}
+		figures = append(figures, value)
+	}
+	return figures
+}
+
+func tokenSet(lower string) map[string]bool {
+	set := make(map[string]bool)
+	for _, token := range groundingTokenPattern.FindAllString(lower, -1) {
+		token = strings.TrimRight(token, ".-")
+		set[token] = true
+		// "react.js" should also satisfy "react"
+		for _, suffix := range []string{".js", "js"} {
+			if base := strings.TrimSuffix(token, suffix); base != token && base != "" {
+				set[base] = true
+			}
+		}
+	}
+	return set
+}
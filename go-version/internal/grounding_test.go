@@ -0,0 +1,103 @@
+package internal_linkedin_scraper
+
+import (
+	"slices"
+	"testing"
+)
+
+const groundingSource = `Acme Payments, Inc. | Senior Go Engineer | Remote (US) | $180k-$220k + equity
+We build payment rails with Go, Postgres and React.js on AWS.
+Apply: jobs [at] acme (dot) example or https:&#x2F;&#x2F;jobs.lever.co&#x2F;acme`
+
+func TestGroundKeepsSupportedFields(t *testing.T) {
+	prev := runStats
+	runStats = newRunStats()
+	t.Cleanup(func() { runStats = prev })
+
+	l := Listing{
+		Company:      "Acme Payments",
+		Contact:      "jobs@acme.example",
+		Pay:          "$180,000-$220,000",
+		Technologies: "Go, PostgreSQL, React",
+	}
+	ground(&l, groundingSource)
+
+	if len(l.Ungrounded) != 0 {
+		t.Errorf("ungrounded %v, want every field supported", l.Ungrounded)
+	}
+	if l.Company != "Acme Payments" || l.Contact == "" || l.Pay == "" {
+		t.Errorf("supported fields were blanked: %+v", l)
+	}
+	if l.Confidence["company"] != 1 || l.Confidence["contact"] != 1 || l.Confidence["pay"] != 1 {
+		t.Errorf("confidence %v, want 1 for company, contact and pay", l.Confidence)
+	}
+	// "PostgreSQL" isn't spelled out in the post
+	if c := l.Confidence["technologies"]; c < groundingThreshold || c >= 1 {
+		t.Errorf("technologies confidence %.2f, want partial support", c)
+	}
+	if rate := runStats.HallucinationRate("company"); rate != 0 {
+		t.Errorf("company hallucination rate %.2f, want 0", rate)
+	}
+}
+
+func TestGroundBlanksUnsupportedFields(t *testing.T) {
+	prev := runStats
+	runStats = newRunStats()
+	t.Cleanup(func() { runStats = prev })
+
+	l := Listing{
+		Company:      "Globex Corporation",
+		Contact:      "careers@globex.example",
+		Pay:          "$250k",
+		Technologies: "Rust, Kafka",
+	}
+	l.enrich()
+	ground(&l, groundingSource)
+
+	if want := []string{"company", "contact", "pay", "technologies"}; !slices.Equal(l.Ungrounded, want) {
+		t.Errorf("ungrounded %v, want %v", l.Ungrounded, want)
+	}
+	if l.Company != "" || l.Contact != "" || l.Pay != "" || l.Technologies != "" {
+		t.Errorf("unsupported fields kept: %+v", l)
+	}
+	// derived fields follow the blanked ones
+	if !l.Compensation.IsZero() {
+		t.Errorf("compensation %+v kept after its pay was blanked", l.Compensation)
+	}
+	if rate := runStats.HallucinationRate("pay"); rate != 1 {
+		t.Errorf("pay hallucination rate %.2f, want 1", rate)
+	}
+
+	prevBlank := BlankUngroundedFields
+	BlankUngroundedFields = false
+	t.Cleanup(func() { BlankUngroundedFields = prevBlank })
+	l = Listing{Company: "Globex Corporation"}
+	ground(&l, groundingSource)
+	if l.Company != "Globex Corporation" || !slices.Equal(l.Ungrounded, []string{"company"}) {
+		t.Errorf("with blanking off got %+v, want the value kept and flagged", l)
+	}
+}
+
+func TestSupportChecks(t *testing.T) {
+	source := "acme | go and react.js | $120-150k | email hiring {at} acme {dot} io or see https://acme.io/jobs"
+	tokens := tokenSet(source)
+	tests := []struct {
+		name  string
+		score float64
+		want  float64
+	}{
+		{"obfuscated email", contactSupport("hiring@acme.io", source, tokens), 1},
+		{"link", contactSupport("https://acme.io/jobs", source, tokens), 1},
+		{"one of two contacts", contactSupport("hiring@acme.io, ceo@acme.io", source, tokens), 0.5},
+		{"pay restated", paySupport("$120,000 - $150,000", source), 1},
+		{"pay made up", paySupport("$200k", source), 0},
+		{"react without .js", technologySupport("React, Go", source, tokens), 1},
+		{"one of two technologies", technologySupport("Go, Elixir", source, tokens), 0.5},
+		{"company words", textSupport("Acme, Inc.", source, tokens), 1},
+	}
+	for _, tt := range tests {
+		if tt.score != tt.want {
+			t.Errorf("%s: support %.2f, want %.2f", tt.name, tt.score, tt.want)
+		}
+	}
+}
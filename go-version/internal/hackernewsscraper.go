@@ -3,17 +3,16 @@ package internal_linkedin_scraper
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
-	"regexp"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/joho/godotenv"
 	"github.com/sashabaranov/go-openai"
 	gpt "github.com/sashabaranov/go-openai"
@@ -25,8 +24,8 @@ var (
 	oaiclient *gpt.Client = nil
 )
 
-func getRawListingsFromURL(URL string) []string {
-	res, err := http.Get(URL)
+func getRawListingsFromURL(URL string) Thread {
+	res, err := httpClient.Get(URL)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -36,39 +35,40 @@ func getRawListingsFromURL(URL string) []string {
 		log.Fatalf("status code error: %d %s", res.StatusCode, res.Status)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(res.Body)
+	thread, err := parseThread(res.Body)
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	toplevelComments := doc.Find("html body center table#hnmain tbody tr td table.comment-tree tbody")
-
-	listings := make([]string, toplevelComments.Length())
-	toplevelComments.Each(func(i int, s *goquery.Selection) {
-		body := s.Find(".commtext")
-
-		listings[i] = body.Text()
-	})
-
-	return listings
+	return thread
 }
 
-func serializeListing(listing string) Listing {
-	if oaiclient == nil {
-		oaiclient = gpt.NewClient(os.Getenv("OPENAI_KEY"))
+// ExtractionModel is the model Run uses to turn comments into listings.
+var ExtractionModel = openai.GPT4oMini
+
+// AttachUpdates makes Run attach the poster's replies under each posting to
+// its listings as Updates.
+var AttachUpdates = false
+
+// maxRepairAttempts is how many times a response that fails schema
+// validation is sent back to the model with the error before giving up.
+const maxRepairAttempts = 2
+
+// strictListingFormat makes the model answer with a Post matching the schema.
+func strictListingFormat() *openai.ChatCompletionResponseFormat {
+	return &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:   "job",
+			Schema: GetListingSchema(),
+			Strict: true,
+		},
 	}
-	client := oaiclient
-	ctx := context.Background()
-	query := `
-	Serialize this into the given type.
-	Make no assumptions.
-	Use empty strings if unsure.
-	The description should remain unchanged and contain the entire text.
-	Return all fields empty if it's not a job listing.`
-	schema := GetListingSchema()
+}
 
-	resp, err := client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: openai.GPT4oMini,
+// extractionRequest is the first request sent to extract a Post from listing.
+func extractionRequest(listing, query string, format *openai.ChatCompletionResponseFormat) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model: ExtractionModel,
 		Messages: []openai.ChatCompletionMessage{
 			{
 				Role:    openai.ChatMessageRoleSystem,
@@ -79,74 +79,243 @@ func serializeListing(listing string) Listing {
 				Content: listing,
 			},
 		},
-		ResponseFormat: &openai.ChatCompletionResponseFormat{
-			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
-			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
-				Name:   "job",
-				Schema: schema,
-				Strict: true,
-			},
-		},
-	})
+		ResponseFormat: format,
+	}
+}
+
+// serializeListing extracts the listings of one comment. Responses that
+// fail validation are repaired, then retried in JSON mode, and as a last
+// resort the comment goes through the rule-based fallback. Any other error,
+// like a bad key or the API being down, is returned: degraded listings
+// would only hide it.
+func serializeListing(listing string) ([]Listing, error) {
+	schema := GetListingSchema()
 
+	prompt := renderPrompt(PromptSerialize, "")
+	post, err := extractPost(listing, prompt.Text, strictListingFormat())
+	if errors.Is(err, ErrBudgetExceeded) {
+		runStats.recordSkipped()
+		return nil, nil
+	}
+	if err != nil && !invalidResponse(err) {
+		return nil, err
+	}
 	if err != nil {
-		log.Fatalf("CreateChatCompletion error: %v", err)
+		log.Printf("[WARN] strict extraction failed, retrying in JSON mode: %v", err)
+		runStats.recordFallback()
+
+		// JSON mode doesn't enforce the schema, so spell it out in the prompt
+		// and rely on schema.Unmarshal to validate locally
+		schemaJSON, _ := json.Marshal(schema)
+		prompt = renderPrompt(PromptSerialize, string(schemaJSON))
+		post, err = extractPost(listing, prompt.Text, &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		})
 	}
 
-	var _listing Listing
-	err = schema.Unmarshal(resp.Choices[0].Message.Content, &_listing)
-	if err != nil {
-		log.Fatalf("Unmarshal schema error: %v", err)
+	var listings []Listing
+	switch {
+	case errors.Is(err, ErrBudgetExceeded):
+		runStats.recordSkipped()
+		return nil, nil
+	case err != nil && !invalidResponse(err):
+		return nil, err
+	case err != nil:
+		log.Printf("[WARN] JSON mode extraction failed, using rule-based fallback: %v", err)
+		listings = fallbackListings(listing)
+		if len(listings) > 0 {
+			runStats.recordDegraded()
+		}
+	default:
+		listings = groundedListings(post, listing, prompt.Version)
+	}
+	runStats.recordComment(len(listings))
+
+	return listings, nil
+}
+
+// groundedListings flattens post, checks each listing against source and
+// stamps it with the version of the prompt that produced it.
+func groundedListings(post Post, source, promptVersion string) []Listing {
+	listings := post.Listings()
+	for i := range listings {
+		ground(&listings[i], source)
+		listings[i].PromptVersion = promptVersion
 	}
+	return listings
+}
+
+// ErrInvalidResponse wraps model responses that couldn't be turned into a
+// valid Post. Only these are worth retrying in another mode.
+var ErrInvalidResponse = errors.New("invalid model response")
+
+// invalidResponse reports whether err means the model answered but not
+// with a valid Post, or that it rejected the strict schema format itself.
+func invalidResponse(err error) bool {
+	if errors.Is(err, ErrInvalidResponse) {
+		return true
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusBadRequest {
+		param := ""
+		if apiErr.Param != nil {
+			param = *apiErr.Param
+		}
+		return strings.HasPrefix(param, "response_format")
+	}
+	return false
+}
+
+// extractPost asks the model to fill in a Post for listing. Responses that
+// fail schema validation are sent back with the error, up to
+// maxRepairAttempts times, before failing with ErrInvalidResponse.
+func extractPost(listing, query string, format *openai.ChatCompletionResponseFormat) (Post, error) {
+	schema := GetListingSchema()
+	req := extractionRequest(listing, query, format)
 
-	return _listing
+	var post Post
+	for attempt := 0; ; attempt++ {
+		resp, err := createChatCompletion(context.Background(), req)
+		if err != nil {
+			return post, fmt.Errorf("CreateChatCompletion error: %w", err)
+		}
+		if len(resp.Choices) == 0 {
+			return post, fmt.Errorf("%w: CreateChatCompletion returned no choices", ErrInvalidResponse)
+		}
+
+		content := resp.Choices[0].Message.Content
+		err = schema.Unmarshal(content, &post)
+		if err == nil {
+			return post, nil
+		}
+		if attempt >= maxRepairAttempts {
+			return post, fmt.Errorf("%w: Unmarshal schema error after %d attempts: %w", ErrInvalidResponse, attempt+1, err)
+		}
+
+		runStats.recordRepair()
+		req.Messages = append(req.Messages,
+			openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: content,
+			},
+			openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleUser,
+				Content: fmt.Sprintf("That response failed validation: %v. Return the corrected JSON only.", err),
+			},
+		)
+	}
 }
 
-func Run(URLs []string) []Listing {
-	listings := make([]string, 0)
+// Run scrapes the threads at URLs and extracts their listings. It fails if
+// any posting couldn't be extracted for a reason other than the run budget
+// or an invalid model response, returning the listings it did get.
+func Run(URLs []string) ([]Listing, error) {
+	runStats = newRunStats()
+	budget = budgetFromEnv()
+
+	// only top-level comments are postings; replies are questions and
+	// answers about them, and deleted postings have nothing left to extract
+	var threads []Thread
+	var postings []Posting
+	var threadOf []int
+	var toplevel []Comment
+	comments, deleted := 0, 0
 	for _, url := range URLs {
-		listings = append(listings, getRawListingsFromURL(url)...)
+		thread := getRawListingsFromURL(url)
+		runStats.recordPage()
+		threads = append(threads, thread)
+		comments += len(thread.Comments)
+
+		for _, posting := range groupPostings(thread.Comments) {
+			if posting.Status() == StatusDeleted {
+				deleted++
+				continue
+			}
+			postings = append(postings, posting)
+			threadOf = append(threadOf, len(threads)-1)
+			toplevel = append(toplevel, posting.Comment)
+		}
 	}
+	fmt.Printf("💬 %d postings, %d deleted, %d replies\n", len(postings), deleted, comments-len(postings)-deleted)
 
-	// flush raw data into a process pool
-	procs := make(chan string, len(listings))
-	for i := 1; i <= len(listings)-1; i++ {
-		procs <- listings[i]
+	// duplicates would cost a model call each for the same listings
+	if MergeDuplicates {
+		before := len(postings)
+		postings, threadOf = dedupePostings(postings, threadOf)
+		toplevel = toplevel[:0]
+		for _, posting := range postings {
+			toplevel = append(toplevel, posting.Comment)
+		}
+		if merged := before - len(postings); merged > 0 {
+			runStats.recordDuplicates(merged)
+			fmt.Printf("🔁 %d duplicate postings merged\n", merged)
+		}
 	}
-	close(procs)
 
-	// tick every second
-	limiter := time.Tick(1000 * time.Millisecond)
+	var serialized [][]Listing
+	var errs []error
+	if BatchMode {
+		serialized, errs = serializeBatch(context.Background(), getOpenAIClient(), toplevel)
+	} else {
+		// serialize every posting concurrently; apiLimiter throttles the API
+		// calls and the results keep their original order
+		serialized = make([][]Listing, len(toplevel))
+		errs = make([]error, len(toplevel))
+		var wg sync.WaitGroup
+		for i, comment := range toplevel {
+			wg.Add(1)
+			go func(index int, processData string) {
+				defer wg.Done()
+				serialized[index], errs[index] = serializeListing(processData)
+			}(i, comment.Text)
+		}
+		wg.Wait()
+	}
 
-	for processData := range procs {
-		<-limiter
-		sL := serializeListing(processData)
-		data, _ := json.Marshal(sL)
-		fmt.Println(string(data))
+	var results []Listing
+	for i, sLs := range serialized {
+		for _, sL := range sLs {
+			sL.CommentID = postings[i].ID
+			sL.Thread = threads[threadOf[i]].ID
+			sL.Month = threads[threadOf[i]].Month
+			sL.SourceCommentIDs = postings[i].SourceIDs()
+			sL.Status = postings[i].Status()
+			if AttachUpdates {
+				sL.Updates = postings[i].Updates()
+			}
+			data, _ := json.Marshal(sL)
+			fmt.Println(string(data))
+			results = append(results, sL)
+		}
 	}
 
-	return nil
+	fmt.Print(runStats.Summary())
+	if _, err := writeReport(getLogsDir()); err != nil {
+		log.Printf("[WARN] %v", err)
+	}
+	// an incomplete run mustn't replace what a thread stored before
+	if err := errors.Join(errs...); err != nil {
+		return results, fmt.Errorf("failed to extract listings: %w", err)
+	}
+	if _, err := writeCSV(getLogsDir(), results); err != nil {
+		log.Printf("[WARN] %v", err)
+	}
+	if err := saveListings(getStoreDir(), results); err != nil {
+		log.Printf("[WARN] %v", err)
+	}
+	return results, nil
 }
 
 // legacy code
 
-const whoIsHiringMessage = `You are browsing a public corporate directory on a website that hosts explicitly consented-to corporate contact information. 
-This information is non-personal and is made publicly available by the corporations for professional outreach. Your task is to extract publicly listed 
-corporate contact details such as company names, publicly listed corporate email addresses, the posted job or role title, and any publicly stated professional 
-information like roles or departments. Additionally, identify and log any publicly available information regarding the company's technical stack, focusing 
-on specific technologies such as React, Vue, Golang, Go, and AWS. Be aware that some emails may be formatted as 'example {at} domain'. Additionally, be aware 
-that React may be referred to as ReactJS, and that Vue may be referred to as VueJS. Furthermore, be thorough in checking over the data you are provided. 
-Never make up any information. Do not include posts or roles that do not include the technologies used anywhere in the post. Do not include posts which 
-themselves include C or any derivative of the C language such as C# or C++. Any framework is okay, TypeScript and JavaScript as generic technologies are okay.
-Do not include languages other than JavaScript, TypeScript, or Go. Ensure that the data you return is accurate. Be extra thorough in checking over contact
-information, and technological stack. This data will be organized into a log format for professional networking purposes, ensuring compliance with all 
-relevant terms of service and privacy policies associated with the website.`
-
 var fileIndex int = 0
 
+// legacyModel is the model used by performAnalyze
+const legacyModel = gpt.GPT4
+
 // Concurrent processing configuration
 const (
-	maxConcurrentRequests = 4     // Reduced from 12 to 4 to respect rate limits
+	maxConcurrentRequests = 4     // Starting point for the adaptive limiter
 	apiRequestDelay       = 2000  // Increased to 2000ms (2 seconds) between chunks
 	preRequestDelay       = 500   // Keep at 500ms
 	retryBaseDelay        = 1000  // Base delay for exponential backoff (1 second)
@@ -209,8 +378,11 @@ var (
 )
 
 // getLogsDir returns the appropriate directory for storing logs
-// Uses /tmp in AWS Lambda (read-write), logs/ locally
+// Uses LOGS_DIR if set, otherwise /tmp in AWS Lambda (read-write), logs/ locally
 func getLogsDir() string {
+	if dir := os.Getenv("LOGS_DIR"); dir != "" {
+		return dir
+	}
 	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
 		return "/tmp"
 	}
@@ -219,7 +391,7 @@ func getLogsDir() string {
 
 func fetchHackerNews(url string) (string, error) {
 	fmt.Printf("Fetching data from URL: %s\n", url)
-	resp, err := http.Get(url)
+	resp, err := httpClient.Get(url)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -254,376 +426,129 @@ func performAnalyze(systemMessage string, data string) (string, error) {
 	}
 	fmt.Println("API Key loaded (first 10 chars):", key[:min(10, len(key))])
 
-	client := gpt.NewClient(key)
 	ctx := context.Background()
 
 	// Reduced delay before making request
 	fmt.Printf("Waiting %.1f seconds before making API request...\n", float64(preRequestDelay)/1000)
 	time.Sleep(time.Duration(preRequestDelay) * time.Millisecond)
 
-	// Enhanced retry logic with exponential backoff
-	maxRetries := 5 // Increased from 3 to 5
-	retries := 0
-
-	for {
-		fmt.Printf("Making API request (attempt %d/%d)...\n", retries+1, maxRetries+1)
-		res, err := client.CreateChatCompletion(ctx, gpt.ChatCompletionRequest{
-			Model: gpt.GPT4,
-			Messages: []gpt.ChatCompletionMessage{
-				{
-					Role:    gpt.ChatMessageRoleSystem,
-					Content: systemMessage + data,
-				},
+	fmt.Println("Making API request...")
+	res, err := createChatCompletion(ctx, gpt.ChatCompletionRequest{
+		Model: legacyModel,
+		Messages: []gpt.ChatCompletionMessage{
+			{
+				Role:    gpt.ChatMessageRoleSystem,
+				Content: systemMessage + data,
 			},
-		})
-
-		if err != nil {
-			fmt.Printf("API Error: %v\n", err)
-
-			// Check for rate limit errors
-			if strings.Contains(err.Error(), "rate_limit_exceeded") ||
-				strings.Contains(err.Error(), "429") ||
-				strings.Contains(err.Error(), "Too Many Requests") ||
-				strings.Contains(strings.ToLower(err.Error()), "rate limit") {
-
-				if retries < maxRetries {
-					retries++
-
-					// Parse wait time from error message if available
-					waitTime := parseRetryAfter(err.Error())
-					if waitTime == 0 {
-						// Exponential backoff: 1s, 2s, 4s, 8s, 16s
-						waitTime = time.Duration(retryBaseDelay<<(retries-1)) * time.Millisecond
-						if waitTime > time.Duration(maxRetryDelay)*time.Millisecond {
-							waitTime = time.Duration(maxRetryDelay) * time.Millisecond
-						}
-					}
-
-					fmt.Printf("🚨 Rate limit hit! Waiting %v before retry (attempt %d/%d)...\n", waitTime, retries, maxRetries)
-					time.Sleep(waitTime)
-					continue
-				} else {
-					return "", fmt.Errorf("max retries exceeded for rate limiting: %v", err)
-				}
-			}
-
-			// For other errors, return immediately
-			return "", err
-		}
-
-		fmt.Println("✅ API request successful!")
+		},
+	})
+	if err != nil {
+		fmt.Printf("API Error: %v\n", err)
+		return "", err
+	}
 
-		// Check if there are any choices and if so, return the content of the first choice.
-		if len(res.Choices) > 0 {
-			return res.Choices[0].Message.Content, nil
-		}
+	fmt.Println("✅ API request successful!")
 
-		// If there are no choices, return an empty string.
-		return "", nil
+	// Check if there are any choices and if so, return the content of the first choice.
+	if len(res.Choices) > 0 {
+		return res.Choices[0].Message.Content, nil
 	}
-}
 
-// parseRetryAfter extracts retry delay from OpenAI error messages
-func parseRetryAfter(errorMsg string) time.Duration {
-	// Look for patterns like "Please try again in 607ms" or "Please try again in 2.5s"
-	if strings.Contains(errorMsg, "Please try again in") {
-		// Extract the time value - this is a simplified parser
-		if strings.Contains(errorMsg, "ms") {
-			// Try to extract milliseconds
-			parts := strings.Split(errorMsg, "Please try again in ")
-			if len(parts) > 1 {
-				timePart := strings.Split(parts[1], "ms")[0]
-				timePart = strings.TrimSpace(timePart)
-				if ms, err := time.ParseDuration(timePart + "ms"); err == nil {
-					return ms
-				}
-			}
-		} else if strings.Contains(errorMsg, "s") {
-			// Try to extract seconds
-			parts := strings.Split(errorMsg, "Please try again in ")
-			if len(parts) > 1 {
-				timePart := strings.Split(parts[1], "s")[0]
-				timePart = strings.TrimSpace(timePart)
-				if s, err := time.ParseDuration(timePart + "s"); err == nil {
-					return s
-				}
-			}
-		}
-	}
-	return 0 // Return 0 if we can't parse the retry time
+	// If there are no choices, return an empty string.
+	return "", nil
 }
 
 // Note: Old saveFile function removed - now using thread-safe saveFileByIndex
 
 func breakUpData(data string) ([]string, error) {
 	fmt.Println("Breaking up data by job posting boundaries...")
-	// Parse HTML and remove all <tr> elements whose DIRECT innerText includes "parent"
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(data))
+	comments, err := parseComments(strings.NewReader(data))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+		return nil, err
 	}
 
-	// Process from innermost to outermost to avoid parent-child conflicts, first pass
-	doc.Find("tr").Each(func(i int, s *goquery.Selection) {
-		// Get only the direct text content of this tr, excluding nested elements
-		directText := ""
-		s.Contents().Each(func(j int, content *goquery.Selection) {
-			// Only collect text nodes that are direct children (not nested in other elements)
-			if goquery.NodeName(content) == "#text" {
-				directText += content.Text()
-			}
-		})
-
-		// Also check immediate child elements (td, th) but not nested tr elements
-		s.Children().Not("tr, table").Each(func(k int, child *goquery.Selection) {
-			childText := child.Text()
-			// Only add if this child doesn't contain nested tr elements
-			if child.Find("tr").Length() == 0 {
-				directText += " " + childText
-			}
-		})
-
-		// Check if this tr should be removed based on its direct content only
-		if strings.Contains(strings.ToLower(directText), "parent") {
-			s.Remove()
+	// replies are questions and answers about a posting, and deleted
+	// comments have no text
+	minifier := customMinifyHTML()
+	var postings []string
+	for _, comment := range groupPostings(comments) {
+		if strings.TrimSpace(comment.Text) == "" {
+			continue
 		}
-	})
-
-	// Start to remove all undesired elements, second pass
-	htmlTagsToRemove := []string{"span", "br", "img", "form", "u", "font"}
-	for _, tag := range htmlTagsToRemove {
-		doc.Find(tag).Each(func(i int, s *goquery.Selection) {
-			s.Remove()
-		})
-	}
-
-	htmlAfterCommentRemoval, err := doc.Html()
-	if err != nil {
-		return nil, fmt.Errorf("failed to render HTML after comment removal: %w", err)
-	}
-
-	stringsToRemove := []string{
-		"<[^>]*>?",
-		"\n",
-		"\\s+",
-		"\\d+ points by \\w+ \\d+ days ago",
-		"hide|past|favorite|\\d+&nbsp;comments",
-		"class=\"comment\">",
-		"<div>",
-		"</div>",
-		"<table>",
-		"</table>",
-		"<td>",
-		"</td>",
-		"<tbody>",
-		"</tbody>",
-		"<th>",
-		"</th>",
-		"<thead>",
-		"</thead>",
-		// HTML document structure tags
-		"<html lang=\"en\" op=\"item\">",
-		"</html>",
-		"<head>",
-		"</head>",
-		"<body>",
-		"</body>",
-		"<title>",
-		"</title>",
-		// Meta and link tags
-		"<meta name=\"referrer\" content=\"origin\"/>",
-		"<meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\"/>",
-		"<link rel=\"stylesheet\" type=\"text/css\" href=\"news.css?c5rFJeaXqANRegCfZJSx\"/>",
-		"<link rel=\"icon\" href=\"y18.svg\"/>",
-		"<link rel=\"canonical\" href=\"https://news.ycombinator.com/item?id=44159528\"/>",
-		// Layout tags
-		"<center>",
-		"</center>",
-		"<p>",
-		"</p>",
-		"<i>",
-		"</i>",
-		"<pre>",
-		"</pre>",
-		"<code>",
-		"</code>",
-		// Table structure tags (excluding tr)
-		"<table id=\"hnmain\" border=\"0\" cellpadding=\"0\" cellspacing=\"0\" width=\"85%\" bgcolor=\"#f6f6ef\">",
-		"<table border=\"0\" cellpadding=\"0\" cellspacing=\"0\" width=\"100%\" style=\"padding:2px\">",
-		"<table class=\"fatitem\" border=\"0\">",
-		"<table border=\"0\" class=\"comment-tree\">",
-		"<table border=\"0\">",
-		// TD with various attributes
-		"<td bgcolor=\"#ff6600\">",
-		"<td style=\"width:18px;padding-right:4px\">",
-		"<td style=\"line-height:12pt; height:10px;\">",
-		"<td style=\"text-align:right;padding-right:4px;\">",
-		"<td align=\"right\" valign=\"top\" class=\"title\">",
-		"<td valign=\"top\" class=\"votelinks\">",
-		"<td class=\"title\">",
-		"<td colspan=\"2\">",
-		"<td class=\"subtext\">",
-		"<td style=\"height:2px\">",
-		"<td style=\"height:10px\">",
-		"<td class=\"ind\" indent=\"0\">",
-		"<td valign=\"top\" class=\"votelinks\">",
-		"<td class=\"default\">",
-		// Div with various classes and styles
-		"<div class=\"toptext\">",
-		"<div style=\"margin-top:2px; margin-bottom:-10px;\">",
-		"<div class=\"commtext c00\">",
-		"<div class=\"reply\">",
-		// Additional common tags
-		"border=\"0\"",
-		"cellpadding=\"0\"",
-		"cellspacing=\"0\"",
-		"width=\"85%\"",
-		"width=\"100%\"",
-		"bgcolor=\"#f6f6ef\"",
-		"bgcolor=\"#ff6600\"",
-		"class=\"athing submission\"",
-		"class=\"athing comtr\"",
-		"class=\"fatitem\"",
-		"class=\"comment-tree\"",
-		"class=\"title\"",
-		"class=\"subtext\"",
-		"class=\"votelinks\"",
-		"class=\"default\"",
-		"class=\"toptext\"",
-		"class=\"commtext c00\"",
-		"class=\"reply\"",
-		"class=\"ind\"",
-		"id=\"44159528\"",
-		"id=\"pagespace\"",
-		"title=\"Ask HN: Who is hiring? (June 2025)\"",
-		"style=\"height:10px\"",
-		"style=\"padding:2px\"",
-		"style=\"width:18px;padding-right:4px\"",
-		"style=\"line-height:12pt; height:10px;\"",
-		"style=\"text-align:right;padding-right:4px;\"",
-		"style=\"height:2px\"",
-		"style=\"margin-top:2px; margin-bottom:-10px;\"",
-		"align=\"right\"",
-		"valign=\"top\"",
-		"indent=\"0\"",
-		"colspan=\"2\"",
-		"<div",
-		"rel=\"nofollow\"",
-		"rel=\"noopener noreferrer\"",
-		"rel=\"noopener\"",
-		"rel=\"noreferrer\"",
-		"rel=\"noreferrer nofollow\"",
-	}
-
-	reformattedData := htmlAfterCommentRemoval
-	for _, str := range stringsToRemove {
-		reformattedData = strings.ReplaceAll(reformattedData, str, "")
-	}
-
-	newDoc, err := goquery.NewDocumentFromReader(strings.NewReader(reformattedData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse reformatted data: %w", err)
-	}
-
-	// Remove all tr elements who have no children
-	newDoc.Find("tr").Each(func(i int, s *goquery.Selection) {
-		if s.Children().Length() == 0 {
-			s.Remove()
+		posting, err := minifier.String("text/html", comment.HTML)
+		if err != nil {
+			return nil, fmt.Errorf("failed to minify HTML: %w", err)
 		}
-	})
-
-	reformattedData, err = newDoc.Html()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get reformatted data: %w", err)
+		postings = append(postings, posting)
 	}
 
-	reformattedData, err = customMinifyHTML().String("text/html", reformattedData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to minify HTML: %w", err)
-	}
-
-	// New intelligent chunking by job posting boundaries
-	chunks, err := chunkByJobPostings(reformattedData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to chunk by job postings: %w", err)
-	}
-
-	// Write chunks to files for debugging
-	// for i, chunk := range chunks {
-	// 	if err := os.WriteFile(fmt.Sprintf("dataChunk-%d.html", i), []byte(chunk), 0644); err != nil {
-	// 		return nil, fmt.Errorf("failed to write dataChunk-%d.html: %w", i, err)
-	// 	}
-	// }
+	chunks := chunkByJobPostings(postings, legacyModel, chunkTokenBudget(legacyModel, renderPrompt(PromptWhoIsHiring, "").Text))
 
 	fmt.Printf("Finished breaking up data into %d intelligent chunks.\n", len(chunks))
 	return chunks, nil
 }
 
-// chunkByJobPostings splits data by job posting boundaries to preserve context
-func chunkByJobPostings(data string) ([]string, error) {
-	// Split by job posting boundaries using the vote link pattern
-	// Each job posting starts with <a id=up_XXXXXXX
-	jobPostingPattern := `<a id=up_\d+`
-	re := regexp.MustCompile(jobPostingPattern)
-
-	// Find all job posting start positions
-	matches := re.FindAllStringIndex(data, -1)
-
-	if len(matches) == 0 {
-		// Fallback to simple chunking if no pattern found
-		fmt.Println("No job posting patterns found, using simple chunking")
-		return simpleChunk(data, 30000), nil // Updated to 30KB to stay under token limit
+// chunkByJobPostings groups whole postings into chunks of at most maxTokens
+// tokens for model. Only postings too large for a chunk of their own are
+// split.
+func chunkByJobPostings(postings []string, model string, maxTokens int) []string {
+	if len(postings) == 0 {
+		fmt.Println("No job postings found")
+		return nil
 	}
 
 	var chunks []string
-	maxChunkSize := 30000 // Reduced to 30KB to stay under GPT-4's 8,192 token limit
 
-	fmt.Printf("Found %d job postings to chunk\n", len(matches))
-
-	for i := 0; i < len(matches); i++ {
-		start := matches[i][0]
-		var end int
-		if i < len(matches)-1 {
-			end = matches[i+1][0]
-		} else {
-			end = len(data)
-		}
-
-		jobPosting := data[start:end]
+	fmt.Printf("Found %d job postings to chunk\n", len(postings))
 
+	for _, jobPosting := range postings {
 		// If a single job posting is too large, split it intelligently
-		if len(jobPosting) > maxChunkSize {
-			subChunks := splitLargeJobPosting(jobPosting, maxChunkSize)
+		if countTokens(model, jobPosting) > maxTokens {
+			subChunks := splitLargeJobPosting(jobPosting, model, maxTokens)
 			chunks = append(chunks, subChunks...)
 		} else {
 			chunks = append(chunks, jobPosting)
 		}
 	}
 
-	// Combine small adjacent chunks to optimize API usage and get closer to 30KB
-	chunks = combineSmallChunks(chunks, maxChunkSize)
+	// Combine small adjacent chunks to optimize API usage and get closer to the token budget
+	chunks = combineSmallChunks(chunks, model, maxTokens)
 
-	return chunks, nil
+	return chunks
 }
 
 // splitLargeJobPosting splits very large job postings while preserving context
-func splitLargeJobPosting(jobPosting string, maxSize int) []string {
+func splitLargeJobPosting(jobPosting string, model string, maxTokens int) []string {
 	// For very large job postings, split by sentences or paragraphs
 	// while keeping context together
 	sentences := strings.Split(jobPosting, ". ")
 	var chunks []string
 	var currentChunk strings.Builder
+	currentTokens := 0
 
 	for _, sentence := range sentences {
+		sentenceTokens := countTokens(model, sentence) + 1
+		// A single sentence over budget has to be cut mid-sentence
+		if sentenceTokens > maxTokens {
+			if currentChunk.Len() > 0 {
+				chunks = append(chunks, currentChunk.String())
+				currentChunk.Reset()
+				currentTokens = 0
+			}
+			chunks = append(chunks, splitByTokens(model, sentence, maxTokens)...)
+			continue
+		}
 		// Add sentence if it fits, otherwise start new chunk
-		if currentChunk.Len()+len(sentence)+2 > maxSize && currentChunk.Len() > 0 {
+		if currentTokens+sentenceTokens > maxTokens && currentChunk.Len() > 0 {
 			chunks = append(chunks, currentChunk.String())
 			currentChunk.Reset()
+			currentTokens = 0
 		}
 		currentChunk.WriteString(sentence)
 		if !strings.HasSuffix(sentence, ".") {
 			currentChunk.WriteString(". ")
 		}
+		currentTokens += sentenceTokens
 	}
 
 	if currentChunk.Len() > 0 {
@@ -633,42 +558,50 @@ func splitLargeJobPosting(jobPosting string, maxSize int) []string {
 	return chunks
 }
 
-// combineSmallChunks combines small job postings to optimize chunk size (targeting 30KB)
-func combineSmallChunks(chunks []string, maxSize int) []string {
+// combineSmallChunks combines small job postings to optimize chunk size (targeting maxTokens)
+func combineSmallChunks(chunks []string, model string, maxTokens int) []string {
 	var combined []string
+	var combinedTokens []int
 	var currentCombined strings.Builder
+	currentTokens := 0
+	separatorTokens := countTokens(model, "\n\n")
 
-	fmt.Printf("🔧 Combining chunks to target %d bytes (%.1fKB)...\n", maxSize, float64(maxSize)/1024)
+	fmt.Printf("🔧 Combining chunks to target %d tokens...\n", maxTokens)
 
 	for i, chunk := range chunks {
+		chunkTokens := countTokens(model, chunk)
+
 		// Try to add chunk to current combined chunk
-		newSize := currentCombined.Len() + len(chunk) + 4 // +4 for separator
+		newTokens := currentTokens + chunkTokens + separatorTokens
 
-		if newSize <= maxSize {
+		if newTokens <= maxTokens {
 			if currentCombined.Len() > 0 {
 				currentCombined.WriteString("\n\n") // Separator between job postings
+				currentTokens += separatorTokens
 			}
 			currentCombined.WriteString(chunk)
-			fmt.Printf("  📝 Added job posting %d to current chunk (now %d bytes)\n", i+1, currentCombined.Len())
+			currentTokens += chunkTokens
+			fmt.Printf("  📝 Added job posting %d to current chunk (now %d tokens)\n", i+1, currentTokens)
 		} else {
 			// Current chunk would exceed limit, finalize it
 			if currentCombined.Len() > 0 {
-				finalSize := currentCombined.Len()
 				combined = append(combined, currentCombined.String())
-				fmt.Printf("  ✅ Finalized chunk #%d: %d bytes (%.1fKB)\n", len(combined), finalSize, float64(finalSize)/1024)
+				combinedTokens = append(combinedTokens, currentTokens)
+				fmt.Printf("  ✅ Finalized chunk #%d: %d tokens\n", len(combined), currentTokens)
 				currentCombined.Reset()
 			}
 			// Start new chunk with current job posting
 			currentCombined.WriteString(chunk)
-			fmt.Printf("  🆕 Started new chunk with job posting %d (%d bytes)\n", i+1, len(chunk))
+			currentTokens = chunkTokens
+			fmt.Printf("  🆕 Started new chunk with job posting %d (%d tokens)\n", i+1, chunkTokens)
 		}
 	}
 
 	// Don't forget the last chunk
 	if currentCombined.Len() > 0 {
-		finalSize := currentCombined.Len()
 		combined = append(combined, currentCombined.String())
-		fmt.Printf("  ✅ Finalized final chunk #%d: %d bytes (%.1fKB)\n", len(combined), finalSize, float64(finalSize)/1024)
+		combinedTokens = append(combinedTokens, currentTokens)
+		fmt.Printf("  ✅ Finalized final chunk #%d: %d tokens\n", len(combined), currentTokens)
 	}
 
 	// Report final statistics
@@ -678,28 +611,14 @@ func combineSmallChunks(chunks []string, maxSize int) []string {
 		totalOriginal, totalCombined, float64(totalOriginal-totalCombined)/float64(totalOriginal)*100)
 
 	// Show size distribution
-	for i, chunk := range combined {
-		size := len(chunk)
-		fmt.Printf("  📦 Chunk %d: %d bytes (%.1fKB) - %.1f%% of target\n",
-			i+1, size, float64(size)/1024, float64(size)/float64(maxSize)*100)
+	for i, tokens := range combinedTokens {
+		fmt.Printf("  📦 Chunk %d: %d tokens - %.1f%% of target\n",
+			i+1, tokens, float64(tokens)/float64(maxTokens)*100)
 	}
 
 	return combined
 }
 
-// simpleChunk provides fallback chunking if pattern matching fails (updated to 30KB)
-func simpleChunk(data string, chunkSize int) []string {
-	var chunks []string
-	for i := 0; i < len(data); i += chunkSize {
-		end := i + chunkSize
-		if end > len(data) {
-			end = len(data)
-		}
-		chunks = append(chunks, data[i:end])
-	}
-	return chunks
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -771,37 +690,23 @@ func filterChunksByKeywords(chunks []string) []string {
 	return filteredChunks
 }
 
-// processChunksConcurrently processes chunks in parallel with controlled concurrency
+// processChunksConcurrently processes chunks in parallel, leaving concurrency to apiLimiter
 func processChunksConcurrently(chunks []string, systemMessage string) ([]string, error) {
 	numChunks := len(chunks)
-	fmt.Printf("🚀 Starting concurrent processing of %d chunks with max %d concurrent requests\n", numChunks, maxConcurrentRequests)
-
-	// Create semaphore to limit concurrent requests
-	semaphore := make(chan struct{}, maxConcurrentRequests)
+	fmt.Printf("🚀 Starting concurrent processing of %d chunks, throttled by the adaptive rate limiter\n", numChunks)
 
 	// Channel to collect results
 	resultChan := make(chan ChunkResult, numChunks)
 
 	var wg sync.WaitGroup
 
-	// Process each chunk concurrently
+	// Process each chunk concurrently; apiLimiter decides how many requests
+	// are actually in flight at once
 	for i, chunk := range chunks {
 		wg.Add(1)
 		go func(index int, data string) {
 			defer wg.Done()
 
-			// Acquire semaphore slot
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			// Add staggered delay to spread out requests and avoid rate limits
-			// Increased stagger delay to reduce rate limit pressure
-			staggerDelay := time.Duration(index*500) * time.Millisecond // Increased from 100ms to 500ms
-			if staggerDelay > 0 {
-				fmt.Printf("⏳ Chunk %d: Staggering request by %v to avoid rate limits\n", index+1, staggerDelay)
-				time.Sleep(staggerDelay)
-			}
-
 			fmt.Printf("🔄 Processing chunk %d/%d...\n", index+1, numChunks)
 
 			result, err := performAnalyze(systemMessage, data)
@@ -827,19 +732,28 @@ func processChunksConcurrently(chunks []string, systemMessage string) ([]string,
 
 	// Collect results maintaining order
 	results := make([]string, numChunks)
-	var errors []string
+	var failures []string
+	skipped := 0
 
 	for result := range resultChan {
-		if result.Error != nil {
-			errors = append(errors, fmt.Sprintf("chunk %d: %v", result.Index+1, result.Error))
-		} else {
+		switch {
+		case errors.Is(result.Error, ErrBudgetExceeded):
+			// left empty; saveResultsToFiles skips it
+			skipped++
+		case result.Error != nil:
+			failures = append(failures, fmt.Sprintf("chunk %d: %v", result.Index+1, result.Error))
+		default:
 			results[result.Index] = result.Content
 		}
 	}
 
 	// Check if we had any errors
-	if len(errors) > 0 {
-		return nil, fmt.Errorf("errors processing chunks: %s", strings.Join(errors, "; "))
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("errors processing chunks: %s", strings.Join(failures, "; "))
+	}
+	if skipped > 0 {
+		fmt.Printf("💸 %d of %d chunks skipped after reaching the run budget\n", skipped, numChunks)
+		return results, nil
 	}
 
 	fmt.Printf("🎉 All %d chunks processed successfully!\n", numChunks)
@@ -909,7 +823,7 @@ func runScript(url string) error {
 	filteredChunks := filterChunksByKeywords(htmlBodyData)
 
 	// Process chunks concurrently
-	systemMessage := whoIsHiringMessage
+	systemMessage := renderPrompt(PromptWhoIsHiring, "").Text
 	results, err := processChunksConcurrently(filteredChunks, systemMessage)
 	if err != nil {
 		return fmt.Errorf("failed to process chunks: %w", err)
@@ -960,10 +874,8 @@ func compileLogs() {
 		if err != nil {
 			log.Fatal(err)
 		}
-
-		fmt.Println("Finished compiling logs.")
-		consolidateLogs()
 	}
+	fmt.Println("Finished compiling logs.")
 }
 
 // consolidate logs into a single file and then return the .html file for download by end-user in the browser
@@ -987,8 +899,9 @@ func consolidateLogs() (string, error) {
 	}
 
 	for _, file := range files {
-		// Skip the compiled file itself if it exists in the directory
-		if file.Name() == "compiled.log" {
+		// Skip the compiled file itself, the last run's report and export, and
+		// the cache and batch directories that share /tmp in Lambda
+		if file.IsDir() || file.Name() == "compiled.log" || file.Name() == reportFileName || file.Name() == csvFileName {
 			continue
 		}
 		filePath := fmt.Sprintf("%s/%s", logsDir, file.Name())
@@ -1008,7 +921,7 @@ func consolidateLogs() (string, error) {
 
 	// Delete all prior logs except the compiled file
 	for _, file := range files {
-		if file.Name() == "compiled.log" {
+		if file.IsDir() || file.Name() == "compiled.log" || file.Name() == reportFileName || file.Name() == csvFileName {
 			continue
 		}
 		filePath := fmt.Sprintf("%s/%s", logsDir, file.Name())
@@ -1031,6 +944,9 @@ func ScrapeHackerNews(pagesToScrape []string) (string, error) {
 	} else {
 		log.Println("[DEBUG] Skipping .env file (AWS Lambda environment)")
 	}
+	runStats = newRunStats()
+	budget = budgetFromEnv()
+	fileIndex = 0
 
 	for _, url := range pagesToScrape {
 		fmt.Printf("Running script for URL: %s\n", url)
@@ -1038,11 +954,18 @@ func ScrapeHackerNews(pagesToScrape []string) (string, error) {
 		if err != nil {
 			log.Fatal(err)
 		}
+		runStats.recordPage()
 	}
 	compileLogs()
 	fileToReturn, err := consolidateLogs()
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// written after consolidating so it isn't folded into the compiled log
+	fmt.Print(runStats.Summary())
+	if _, err := writeReport(getLogsDir()); err != nil {
+		log.Printf("[WARN] %v", err)
+	}
 	return fileToReturn, nil
 }
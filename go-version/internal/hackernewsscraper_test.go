@@ -0,0 +1,252 @@
+package internal_linkedin_scraper
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The fixtures hold a small hand-written "Who is hiring?" thread and canned
+// model answers for it, so replaying them checks the requests the scraper
+// sends and how it handles the answers, not what a real model would say; see
+// testdata/fixtures/README.md. The -record flag captures real traffic in
+// their place:
+//
+//	go test ./internal -record
+var recordFixtures = flag.Bool("record", false, "record fixtures from the network instead of replaying them")
+
+const (
+	fixturesDir = "testdata/fixtures"
+	threadURL   = "https://news.ycombinator.com/item?id=44159528"
+)
+
+// useFixtures replays the fixtures, or records them with -record, and gives
+// the test fresh run state and its own directories for the logs, the run
+// report and the listing store.
+func useFixtures(t *testing.T) {
+	t.Helper()
+	mode := FixturesReplay
+	if *recordFixtures {
+		mode = FixturesRecord
+	}
+	dir, err := filepath.Abs(fixturesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	useScratchDirs(t)
+	prevClient, prevStats, prevBudget, prevCache := oaiclient, runStats, budget, CacheEnabled
+	t.Cleanup(func() {
+		oaiclient, runStats, budget, CacheEnabled = prevClient, prevStats, prevBudget, prevCache
+	})
+
+	t.Cleanup(UseFixtures(dir, mode))
+	oaiclient = nil
+	runStats = newRunStats()
+	budget = &runBudget{}
+	CacheEnabled = false
+	if !*recordFixtures {
+		t.Setenv("OPENAI_KEY", "test-key")
+	}
+}
+
+func TestGetRawListingsFromURL(t *testing.T) {
+	useFixtures(t)
+
+	thread := getRawListingsFromURL(threadURL)
+	if thread.ID != "44159528" || thread.Month != "2025-06" {
+		t.Errorf("thread ID, month = %q, %q; want 44159528, 2025-06", thread.ID, thread.Month)
+	}
+	comments := thread.Comments
+
+	wantIDs := []string{"44159700", "44159701", "44159702", "44159710", "44159720"}
+	if len(comments) != len(wantIDs) {
+		t.Fatalf("got %d comments, want %d", len(comments), len(wantIDs))
+	}
+	for i, id := range wantIDs {
+		if comments[i].ID != id {
+			t.Errorf("comment %d ID = %q, want %q", i, comments[i].ID, id)
+		}
+	}
+	acme := comments[0]
+	if !strings.HasPrefix(acme.Text, "Acme Payments | Senior Go Engineer") {
+		t.Errorf("first comment = %q, want the Acme post", acme.Text)
+	}
+	if acme.Author != "acmejobs" || acme.Age != "3 hours ago" || acme.Posted.Unix() != 1748876472 {
+		t.Errorf("Acme comment header = %q, %q, %v", acme.Author, acme.Age, acme.Posted)
+	}
+	if !strings.Contains(acme.HTML, `href="https://jobs.acme.example/go-engineer"`) {
+		t.Errorf("Acme comment HTML lost its link: %q", acme.HTML)
+	}
+	if !strings.Contains(acme.Text, "+ equity | Full-time\n\nWe build") || !strings.Contains(acme.Text, "Apply: https://jobs.acme.example/go-engineer or") {
+		t.Errorf("Acme comment text lost its paragraphs or link: %q", acme.Text)
+	}
+	wantParents := []string{"", "44159700", "44159701", "", ""}
+	for i, want := range []int{0, 1, 2, 0, 0} {
+		if comments[i].Indent != want || comments[i].Parent != wantParents[i] {
+			t.Errorf("comment %s indent, parent = %d, %q; want %d, %q", comments[i].ID, comments[i].Indent, comments[i].Parent, want, wantParents[i])
+		}
+	}
+}
+
+func TestBreakUpData(t *testing.T) {
+	useFixtures(t)
+
+	data, err := fetchHackerNews(threadURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := breakUpData(data)
+	if err != nil {
+		t.Fatalf("breakUpData: %v", err)
+	}
+	if len(chunks) == 0 {
+		t.Fatal("no chunks")
+	}
+
+	all := strings.Join(chunks, "")
+	for _, want := range []string{"Acme Payments", "Globex", "Initech"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("chunks are missing %q", want)
+		}
+	}
+	// replies carry a "parent" link and are dropped
+	if strings.Contains(all, "only US and Canada") {
+		t.Error("chunks still contain a reply")
+	}
+	budget := chunkTokenBudget(legacyModel, renderPrompt(PromptWhoIsHiring, "").Text)
+	for i, chunk := range chunks {
+		if n := countTokens(legacyModel, chunk); n > budget {
+			t.Errorf("chunk %d has %d tokens, over the budget of %d", i, n, budget)
+		}
+	}
+}
+
+func TestChunkByJobPostings(t *testing.T) {
+	postings := []string{
+		`Acme | Go Engineer | Remote. We use Go and AWS. Email jobs@acme.example.`,
+		`Globex | Frontend Engineer | Berlin. React and TypeScript.`,
+		strings.Repeat("Initech builds printer firmware in modern C++. ", 40),
+	}
+
+	tests := []struct {
+		name       string
+		maxTokens  int
+		wantChunks int
+	}{
+		{"everything fits in one chunk", 10000, 1},
+		{"large posting is split", 60, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := chunkByJobPostings(postings, legacyModel, tt.maxTokens)
+			if tt.wantChunks > 0 && len(chunks) != tt.wantChunks {
+				t.Errorf("got %d chunks, want %d", len(chunks), tt.wantChunks)
+			}
+			if tt.wantChunks == 0 && len(chunks) < 3 {
+				t.Errorf("got %d chunks, want the postings split up", len(chunks))
+			}
+			for i, chunk := range chunks {
+				if n := countTokens(legacyModel, chunk); n > tt.maxTokens {
+					t.Errorf("chunk %d has %d tokens, over %d", i, n, tt.maxTokens)
+				}
+			}
+			// postings are never cut across a boundary
+			if !strings.HasPrefix(chunks[0], "Acme |") {
+				t.Errorf("first chunk starts with %.20q, want the first posting", chunks[0])
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	useFixtures(t)
+
+	listings, err := Run([]string{threadURL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byCompany := make(map[string]Listing)
+	for _, l := range listings {
+		byCompany[l.Company] = l
+	}
+	if len(byCompany) != 3 {
+		t.Fatalf("got listings for %d companies, want 3: %+v", len(byCompany), listings)
+	}
+
+	acme, ok := byCompany["Acme Payments"]
+	if !ok {
+		t.Fatal("no Acme Payments listing")
+	}
+	if acme.CommentID != "44159700" || acme.Status != StatusOpen {
+		t.Errorf("Acme comment ID, status = %q, %q; want 44159700, open", acme.CommentID, acme.Status)
+	}
+	if acme.Compensation.Min != 150000 || acme.Compensation.Max != 180000 {
+		t.Errorf("Acme compensation = %+v, want 150000-180000", acme.Compensation)
+	}
+	if !acme.WorkLocation.Allows(PolicyRemote) {
+		t.Errorf("Acme work location = %+v, want remote", acme.WorkLocation)
+	}
+	if globex := byCompany["Globex"]; globex.WorkLocation.Visa != VisaYes {
+		t.Errorf("Globex visa = %q, want %q", globex.WorkLocation.Visa, VisaYes)
+	}
+
+	report := runStats.Report()
+	// the two replies under the Acme posting aren't postings themselves
+	if report.Pages != 1 || report.Comments != 3 {
+		t.Errorf("report counted %d pages, %d comments; want 1, 3", report.Pages, report.Comments)
+	}
+	if report.PromptTokens == 0 {
+		t.Error("report recorded no token usage")
+	}
+	if _, err := os.Stat(filepath.Join(getLogsDir(), reportFileName)); err != nil {
+		t.Errorf("run report not written: %v", err)
+	}
+
+	stored, err := LoadListings(os.Getenv("LISTINGS_DIR"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != len(listings) || stored[0].Month != "2025-06" || stored[0].Thread != "44159528" {
+		t.Errorf("stored %d listings, first %+v; want %d from the June 2025 thread", len(stored), stored[0], len(listings))
+	}
+}
+
+func TestRunAttachesPosterReplies(t *testing.T) {
+	useFixtures(t)
+	prev := AttachUpdates
+	AttachUpdates = true
+	t.Cleanup(func() { AttachUpdates = prev })
+
+	listings, err := Run([]string{threadURL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range listings {
+		switch l.Company {
+		case "Acme Payments":
+			// the question from another user is left out
+			if len(l.Updates) != 1 || l.Updates[0].CommentID != "44159702" || l.Updates[0].Text != "Sorry, only US and Canada for now." {
+				t.Errorf("Acme updates = %+v, want the poster's reply", l.Updates)
+			}
+		default:
+			if len(l.Updates) != 0 {
+				t.Errorf("%s updates = %+v, want none", l.Company, l.Updates)
+			}
+		}
+	}
+}
+
+func TestFixturesReplayFailsForUnrecordedRequests(t *testing.T) {
+	useFixtures(t)
+	if *recordFixtures {
+		t.Skip("only meaningful when replaying")
+	}
+
+	_, err := httpClient.Get("https://news.ycombinator.com/item?id=1")
+	if err == nil || !strings.Contains(err.Error(), "no fixture") {
+		t.Fatalf("err = %v, want a missing fixture error", err)
+	}
+}
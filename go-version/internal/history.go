@@ -0,0 +1,252 @@
+package internal_linkedin_scraper
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// CompanyMonth is what a company posted in one monthly thread, and how that
+// changed since the last month it posted.
+type CompanyMonth struct {
+	Month        string   `json:"month"`
+	Listings     int      `json:"listings"`
+	CommentIDs   []string `json:"commentIds"`
+	Titles       []string `json:"titles"`
+	Technologies []string `json:"technologies"`
+	// Pay is the annualized range over the month's listings that state
+	// pay, in the currency most of them use
+	Pay PayRange `json:"pay"`
+
+	NewTitles     []string `json:"newTitles,omitempty"`
+	DroppedTitles []string `json:"droppedTitles,omitempty"`
+	NewTech       []string `json:"newTech,omitempty"`
+	DroppedTech   []string `json:"droppedTech,omitempty"`
+	PayChanged    bool     `json:"payChanged,omitempty"`
+}
+
+// PayRange is an annual pay range. Zero values mean no listing stated pay.
+type PayRange struct {
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Currency string  `json:"currency"`
+}
+
+func (p PayRange) String() string {
+	if p.Min == 0 && p.Max == 0 {
+		return "no pay stated"
+	}
+	if p.Max == 0 || p.Max == p.Min {
+		return fmt.Sprintf("%s %s", formatAmount(p.Min), p.Currency)
+	}
+	return fmt.Sprintf("%s–%s %s", formatAmount(p.Min), formatAmount(p.Max), p.Currency)
+}
+
+func formatAmount(v float64) string {
+	if v >= 1000 {
+		return fmt.Sprintf("%.0fk", v/1000)
+	}
+	return fmt.Sprintf("%.0f", v)
+}
+
+// CompanyHistory is a company's posting history across the stored months.
+type CompanyHistory struct {
+	Company
+	FirstSeen string         `json:"firstSeen"`
+	LastSeen  string         `json:"lastSeen"`
+	Months    []CompanyMonth `json:"months"`
+	// New is set for companies whose first posting is in the latest month
+	// stored
+	New bool `json:"new"`
+}
+
+// BuildHistory resolves the companies behind listings and lays out, per
+// company, the months it posted in. The result is sorted by company key.
+func BuildHistory(listings []Listing) []CompanyHistory {
+	companies, index := ResolveCompanies(listings)
+
+	latest := ""
+	byCompany := make([]map[string][]Listing, len(companies))
+	for i, l := range listings {
+		if l.Month > latest {
+			latest = l.Month
+		}
+		c := index[i]
+		if c < 0 || l.Month == "" {
+			continue
+		}
+		if byCompany[c] == nil {
+			byCompany[c] = make(map[string][]Listing)
+		}
+		byCompany[c][l.Month] = append(byCompany[c][l.Month], l)
+	}
+
+	histories := make([]CompanyHistory, 0, len(companies))
+	for c, company := range companies {
+		if len(byCompany[c]) == 0 {
+			continue
+		}
+		months := make([]string, 0, len(byCompany[c]))
+		for month := range byCompany[c] {
+			months = append(months, month)
+		}
+		sort.Strings(months)
+
+		h := CompanyHistory{Company: company, FirstSeen: months[0], LastSeen: months[len(months)-1]}
+		h.New = h.FirstSeen == latest
+		for i, month := range months {
+			m := summarizeMonth(month, byCompany[c][month])
+			if i > 0 {
+				compareMonths(&m, h.Months[i-1])
+			}
+			h.Months = append(h.Months, m)
+		}
+		histories = append(histories, h)
+	}
+	return histories
+}
+
+func summarizeMonth(month string, listings []Listing) CompanyMonth {
+	m := CompanyMonth{Month: month, Listings: len(listings)}
+	currencies := make(map[string]int)
+	for _, l := range listings {
+		if !slices.Contains(m.CommentIDs, l.CommentID) && l.CommentID != "" {
+			m.CommentIDs = append(m.CommentIDs, l.CommentID)
+		}
+		m.Titles = appendFold(m.Titles, strings.TrimSpace(l.Title))
+		for _, tech := range splitTechnologies(l.Technologies) {
+			m.Technologies = appendFold(m.Technologies, tech)
+		}
+		if !l.Compensation.IsZero() {
+			currencies[l.Compensation.Currency]++
+		}
+	}
+
+	for currency, n := range currencies {
+		if n > currencies[m.Pay.Currency] || n == currencies[m.Pay.Currency] && currency < m.Pay.Currency {
+			m.Pay.Currency = currency
+		}
+	}
+	for _, l := range listings {
+		if l.Compensation.IsZero() || l.Compensation.Currency != m.Pay.Currency {
+			continue
+		}
+		low, high := l.Compensation.Annualized()
+		// "up to $200k" states no bottom to compare
+		if low > 0 && (m.Pay.Min == 0 || low < m.Pay.Min) {
+			m.Pay.Min = low
+		}
+		if high > m.Pay.Max {
+			m.Pay.Max = high
+		}
+	}
+	return m
+}
+
+// compareMonths fills in what changed in m since prev.
+func compareMonths(m *CompanyMonth, prev CompanyMonth) {
+	m.NewTitles = missingFold(m.Titles, prev.Titles)
+	m.DroppedTitles = missingFold(prev.Titles, m.Titles)
+	m.NewTech = missingFold(m.Technologies, prev.Technologies)
+	m.DroppedTech = missingFold(prev.Technologies, m.Technologies)
+	m.PayChanged = m.Pay != prev.Pay && (m.Pay.Min != 0 || m.Pay.Max != 0) && (prev.Pay.Min != 0 || prev.Pay.Max != 0)
+}
+
+// appendFold appends s to list unless it is empty or already there, ignoring
+// case.
+func appendFold(list []string, s string) []string {
+	if s == "" || slices.ContainsFunc(list, func(v string) bool { return strings.EqualFold(v, s) }) {
+		return list
+	}
+	return append(list, s)
+}
+
+// missingFold returns the values of a that aren't in b, ignoring case.
+func missingFold(a, b []string) []string {
+	var missing []string
+	for _, v := range a {
+		if !slices.ContainsFunc(b, func(w string) bool { return strings.EqualFold(v, w) }) {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+// FindHistory returns the history of the company query names, matched on
+// its normalized name, any spelling of it or a domain.
+func FindHistory(histories []CompanyHistory, query string) (CompanyHistory, bool) {
+	key := NormalizeCompanyName(query)
+	for _, h := range histories {
+		if h.Key == key || slices.Contains(h.Domains, strings.ToLower(query)) {
+			return h, true
+		}
+		for _, name := range h.Names {
+			if NormalizeCompanyName(name) == key {
+				return h, true
+			}
+		}
+	}
+	return CompanyHistory{}, false
+}
+
+// HistoryTable lists the companies one per line: when they first and last
+// posted, in how many months, and whether they are new.
+func HistoryTable(histories []CompanyHistory) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🏢 %d companies\n", len(histories))
+	fmt.Fprintf(&b, "  %-32s %-8s %-8s %6s\n", "company", "first", "last", "months")
+	for _, h := range histories {
+		marker := ""
+		if h.New {
+			marker = " 🆕"
+		}
+		fmt.Fprintf(&b, "  %-32s %-8s %-8s %6d%s\n", truncate(h.Name, 32), h.FirstSeen, h.LastSeen, len(h.Months), marker)
+	}
+	return b.String()
+}
+
+// Summary renders a company's history month by month, with what changed.
+func (h CompanyHistory) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🏢 %s", h.Name)
+	if len(h.Domains) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(h.Domains, ", "))
+	}
+	fmt.Fprintf(&b, ": posted in %d months since %s", len(h.Months), h.FirstSeen)
+	if h.New {
+		b.WriteString(" 🆕 new poster")
+	}
+	b.WriteString("\n")
+	if others := slices.DeleteFunc(slices.Clone(h.Names), func(n string) bool { return n == h.Name }); len(others) > 0 {
+		fmt.Fprintf(&b, "  also posted as %s\n", strings.Join(others, ", "))
+	}
+	for _, m := range h.Months {
+		fmt.Fprintf(&b, "  📅 %s  %d listings: %s | %s | %s\n", m.Month, m.Listings,
+			strings.Join(m.Titles, ", "), m.Pay, strings.Join(m.Technologies, ", "))
+		if len(m.NewTitles) > 0 {
+			fmt.Fprintf(&b, "     + roles: %s\n", strings.Join(m.NewTitles, ", "))
+		}
+		if len(m.DroppedTitles) > 0 {
+			fmt.Fprintf(&b, "     − roles: %s\n", strings.Join(m.DroppedTitles, ", "))
+		}
+		if len(m.NewTech) > 0 {
+			fmt.Fprintf(&b, "     + tech: %s\n", strings.Join(m.NewTech, ", "))
+		}
+		if len(m.DroppedTech) > 0 {
+			fmt.Fprintf(&b, "     − tech: %s\n", strings.Join(m.DroppedTech, ", "))
+		}
+		if m.PayChanged {
+			fmt.Fprintf(&b, "     💵 pay changed\n")
+		}
+	}
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,93 @@
+package internal_linkedin_scraper
+
+import (
+	"testing"
+)
+
+func TestNormalizeCompanyName(t *testing.T) {
+	tests := map[string]string{
+		"Acme, Inc. (YC W21)":    "acme",
+		"ACME":                   "acme",
+		"acme.io":                "acme",
+		"https://www.acme.io/":   "acme",
+		"careers.acme.co.uk":     "acme",
+		"jobs.abc.io":            "abc",
+		"The Globex Corporation": "globex",
+		"Initech GmbH":           "initech",
+		"Stripe & Co":            "stripe co",
+		"The Browser Company":    "browser company",
+		"AS Labs":                "as labs",
+		"AT&T":                   "at&t",
+	}
+	for name, want := range tests {
+		if got := NormalizeCompanyName(name); got != want {
+			t.Errorf("NormalizeCompanyName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestResolveCompaniesKeepsSharedHostsApart(t *testing.T) {
+	listings := []Listing{
+		{Company: "Acme", Contact: "https://www.ycombinator.com/companies/acme"},
+		{Company: "Globex", Contact: "https://ycombinator.com/companies/globex/jobs/x1-engineer"},
+		{Company: "Initech", Contact: "https://www.workatastartup.com/jobs/123"},
+		{Company: "Hooli", Contact: "https://www.workatastartup.com/jobs/456"},
+		{Company: "Acme Rockets", Contact: "https://www.ycombinator.com/companies/acme/jobs"},
+	}
+	companies, index := ResolveCompanies(listings)
+	if len(companies) != 4 {
+		t.Fatalf("got %d companies, want 4: %+v", len(companies), companies)
+	}
+	if index[0] != index[4] {
+		t.Error("the two Acme listings on the same YC page weren't merged")
+	}
+	for i, j := range [][2]int{{0, 1}, {2, 3}} {
+		if index[j[0]] == index[j[1]] {
+			t.Errorf("case %d: %s and %s merged through a shared host", i, listings[j[0]].Company, listings[j[1]].Company)
+		}
+	}
+	if c := companies[index[1]]; len(c.Domains) != 1 || c.Domains[0] != "ycombinator.com/globex" {
+		t.Errorf("Globex domains %v, want its YC page", c.Domains)
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	listings := []Listing{
+		enriched(Listing{Month: "2025-04", Company: "Acme Payments", Title: "Senior Go Engineer", Pay: "$150k-$180k", Technologies: "Go, AWS", Contact: "jobs@acme.example"}),
+		enriched(Listing{Month: "2025-05", Company: "Acme Payments, Inc.", Title: "Senior Go Engineer", Pay: "$150k-$180k", Technologies: "Go, AWS", Contact: "https://jobs.lever.co/acme"}),
+		// renamed, but the same Lever board ties it to Acme
+		enriched(Listing{Month: "2025-06", Company: "Acme", Title: "Staff Engineer", Pay: "$190k-$230k", Technologies: "Go, AWS, Rust", Contact: "https://jobs.lever.co/acme/123"}),
+		enriched(Listing{Month: "2025-06", Company: "Globex", Title: "Frontend Engineer", Pay: "€70k-€85k", Technologies: "React", Contact: "hr@globex.example"}),
+		// different companies on the same board stay apart
+		enriched(Listing{Month: "2025-06", Company: "Initech", Title: "Firmware Engineer", Technologies: "C++", Contact: "https://jobs.lever.co/initech"}),
+		enriched(Listing{Month: "2025-06", Contact: "me@gmail.com"}),
+	}
+
+	histories := BuildHistory(listings)
+	if len(histories) != 3 {
+		t.Fatalf("got %d companies, want 3: %+v", len(histories), histories)
+	}
+
+	acme, ok := FindHistory(histories, "acme payments")
+	if !ok {
+		t.Fatal("no history for Acme")
+	}
+	if len(acme.Months) != 3 || acme.FirstSeen != "2025-04" || acme.New {
+		t.Errorf("Acme history = %+v, want 3 months since 2025-04", acme)
+	}
+	june := acme.Months[2]
+	if len(june.NewTitles) != 1 || june.NewTitles[0] != "Staff Engineer" || len(june.DroppedTitles) != 1 {
+		t.Errorf("June roles +%q −%q, want Staff Engineer in for Senior Go Engineer", june.NewTitles, june.DroppedTitles)
+	}
+	if len(june.NewTech) != 1 || june.NewTech[0] != "Rust" || !june.PayChanged {
+		t.Errorf("June changes = %+v, want Rust added and pay changed", june)
+	}
+	if acme.Months[1].PayChanged {
+		t.Error("May pay marked as changed")
+	}
+
+	globex, ok := FindHistory(histories, "globex.example")
+	if !ok || !globex.New {
+		t.Errorf("Globex = %+v, want a new poster found by domain", globex)
+	}
+}
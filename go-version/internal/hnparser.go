@@ -0,0 +1,192 @@
+package internal_linkedin_scraper
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Comment is a single HN comment.
+type Comment struct {
+	ID string
+	// Indent is the comment's depth in the thread; top-level comments are 0
+	// and have no Parent
+	Indent int
+	Parent string
+	Author string
+	// Age is HN's relative timestamp, like "3 hours ago", and Posted the
+	// time it stands for
+	Age    string
+	Posted time.Time
+	Text   string
+	// HTML is the comment body as HN serves it, links included
+	HTML string
+	// Status is open, filled, flagged or deleted
+	Status string
+}
+
+// Thread is an HN item page: the story and its comments.
+type Thread struct {
+	ID    string
+	Title string
+	// Month is the month the thread is for, as "2006-01": the one in a
+	// "Who is hiring? (June 2025)" title, or else when it was posted
+	Month    string
+	Comments []Comment
+}
+
+var threadMonthPattern = regexp.MustCompile(`\((January|February|March|April|May|June|July|August|September|October|November|December) (\d{4})\)`)
+
+// parseThread reads an HN item page. Like parseComments, it relies only on
+// HN's markup, not on a particular thread.
+func parseThread(r io.Reader) (Thread, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return Thread{}, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	story := doc.Find("tr.athing.submission").First()
+	thread := Thread{
+		Title:    strings.TrimSpace(story.Find(".titleline").First().Text()),
+		Comments: commentsOf(doc),
+	}
+	thread.ID, _ = story.Attr("id")
+
+	if m := threadMonthPattern.FindStringSubmatch(thread.Title); m != nil {
+		month, _ := time.Parse("January 2006", m[1]+" "+m[2])
+		thread.Month = month.Format("2006-01")
+	} else if title, ok := doc.Find(".subtext span.age").First().Attr("title"); ok && !parseAgeTitle(title).IsZero() {
+		thread.Month = parseAgeTitle(title).Format("2006-01")
+	} else if len(thread.Comments) > 0 && !thread.Comments[0].Posted.IsZero() {
+		thread.Month = thread.Comments[0].Posted.Format("2006-01")
+	}
+	return thread, nil
+}
+
+// parseComments reads every comment of an HN item page in thread order. It
+// relies only on HN's comment markup, so it works for any thread or month.
+func parseComments(r io.Reader) ([]Comment, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	return commentsOf(doc), nil
+}
+
+func commentsOf(doc *goquery.Document) []Comment {
+	// HN renders the tree as a flat list of rows, one per comment
+	rows := doc.Find("tr.athing.comtr")
+	comments := make([]Comment, 0, rows.Length())
+	// ancestors[d] is the ID of the last comment seen at depth d
+	var ancestors []string
+	rows.Each(func(i int, row *goquery.Selection) {
+		id, ok := row.Attr("id")
+		if !ok {
+			return
+		}
+		comment := Comment{ID: id}
+
+		if indent, ok := row.Find("td.ind").First().Attr("indent"); ok {
+			comment.Indent, _ = strconv.Atoi(indent)
+		}
+		if comment.Indent > len(ancestors) {
+			// a skipped level means a missing row; hang it off the deepest one
+			comment.Indent = len(ancestors)
+		}
+		ancestors = append(ancestors[:comment.Indent], id)
+		if comment.Indent > 0 {
+			comment.Parent = ancestors[comment.Indent-1]
+		}
+		comment.Author = strings.TrimSpace(row.Find(".comhead a.hnuser").First().Text())
+
+		age := row.Find(".comhead span.age").First()
+		comment.Age = strings.TrimSpace(age.Text())
+		if title, ok := age.Attr("title"); ok {
+			comment.Posted = parseAgeTitle(title)
+		}
+
+		// older markup nests the reply link inside the comment body
+		body := row.Find(".commtext").First().Clone()
+		body.Find(".reply").Remove()
+		comment.Text = commentText(body)
+		comment.HTML, _ = body.Html()
+		comment.Status = commentStatus(row, comment.Text)
+
+		comments = append(comments, comment)
+	})
+
+	return comments
+}
+
+// Posting is a top-level comment together with every reply below it.
+// Duplicates are other postings of the same text merged into this one.
+type Posting struct {
+	Comment
+	Replies    []Comment
+	Duplicates []Comment
+}
+
+// SourceIDs are the ids of the posting and of every duplicate merged into
+// it, or nil if it has none.
+func (p Posting) SourceIDs() []string {
+	if len(p.Duplicates) == 0 {
+		return nil
+	}
+	ids := []string{p.ID}
+	for _, d := range p.Duplicates {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+// groupPostings turns the comments of a thread, in thread order, into its
+// top-level postings. Replies before the first top-level comment, as on a
+// page that starts mid-thread, are dropped.
+func groupPostings(comments []Comment) []Posting {
+	var postings []Posting
+	for _, comment := range comments {
+		if comment.Indent == 0 {
+			postings = append(postings, Posting{Comment: comment})
+			continue
+		}
+		if len(postings) > 0 {
+			last := &postings[len(postings)-1]
+			last.Replies = append(last.Replies, comment)
+		}
+	}
+	return postings
+}
+
+// Updates are the replies the poster wrote under their own posting, like
+// clarifications or "this role has been filled".
+func (p Posting) Updates() []Update {
+	var updates []Update
+	for _, reply := range p.Replies {
+		if reply.Author != "" && reply.Author == p.Author && strings.TrimSpace(reply.Text) != "" {
+			updates = append(updates, Update{CommentID: reply.ID, Text: reply.Text, Posted: reply.Posted})
+		}
+	}
+	return updates
+}
+
+// parseAgeTitle reads the title of an age span, "2025-06-02T15:01:12
+// 1748876472": an ISO timestamp followed by the Unix time.
+func parseAgeTitle(title string) time.Time {
+	fields := strings.Fields(title)
+	if len(fields) > 1 {
+		if unix, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			return time.Unix(unix, 0).UTC()
+		}
+	}
+	if len(fields) > 0 {
+		if t, err := time.Parse("2006-01-02T15:04:05", fields[0]); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,99 @@
+package internal_linkedin_scraper
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Seniority levels a Position may be tagged with. Listings stored before the
+// richer enum only used junior, mid and senior; SeniorityBand maps every
+// level back onto those three so old and new data can be compared.
+const (
+	LevelIntern    = "intern"
+	LevelJunior    = "junior"
+	LevelMid       = "mid"
+	LevelSenior    = "senior"
+	LevelStaff     = "staff"
+	LevelPrincipal = "principal"
+	LevelLead      = "lead"
+	LevelManager   = "manager"
+	LevelDirector  = "director"
+)
+
+// Employment types a Position may be tagged with
+const (
+	EmploymentFullTime   = "full-time"
+	EmploymentPartTime   = "part-time"
+	EmploymentContract   = "contract"
+	EmploymentInternship = "internship"
+)
+
+var (
+	// levelAliases maps loose spellings to a level, checked in order so
+	// "senior staff" is staff and "engineering manager" is manager
+	levelAliases = []struct {
+		level   string
+		pattern *regexp.Regexp
+	}{
+		{LevelDirector, regexp.MustCompile(`\b(?:director|vp|vice president|head of|cto|chief)\b`)},
+		{LevelManager, regexp.MustCompile(`\b(?:manager|mgr)\b`)},
+		{LevelPrincipal, regexp.MustCompile(`\b(?:principal|distinguished|fellow)\b`)},
+		{LevelStaff, regexp.MustCompile(`\bstaff\b`)},
+		// "lead" only counts next to a role, so "Lead Generation" isn't a level
+		{LevelLead, regexp.MustCompile(`^lead$|\b(?:tech|team|technical|engineering|dev|development)\s+lead\b|\blead\s+(?:software\s+|frontend\s+|front-end\s+|backend\s+|back-end\s+|full[- ]?stack\s+|mobile\s+|data\s+|ml\s+|qa\s+|platform\s+|product\s+)?(?:engineer|developer|dev|programmer|designer|scientist|architect|sre|devops)s?\b|\barchitect\b`)},
+		{LevelIntern, regexp.MustCompile(`\b(?:intern|internship|co-?op|student)\b`)},
+		{LevelSenior, regexp.MustCompile(`\b(?:senior|sr\.?|experienced|l5|e5)\b`)},
+		{LevelJunior, regexp.MustCompile(`\b(?:junior|jr\.?|entry[- ]level|new grad|graduate|associate)\b`)},
+		{LevelMid, regexp.MustCompile(`\b(?:mid|mid-level|intermediate)\b`)},
+	}
+
+	employmentPatterns = []struct {
+		employment string
+		pattern    *regexp.Regexp
+	}{
+		{EmploymentInternship, regexp.MustCompile(`\b(?:intern|internship|co-?op)\b`)},
+		{EmploymentContract, regexp.MustCompile(`\b(?:contract|contractor|freelance|freelancer|consultant|c2c|1099)\b`)},
+		{EmploymentPartTime, regexp.MustCompile(`\bpart[- ]?time\b`)},
+		{EmploymentFullTime, regexp.MustCompile(`\b(?:full[- ]?time|fte|permanent)\b`)},
+	}
+)
+
+// NormalizeLevel maps free-text or legacy seniority onto one of the Level
+// constants, or "" when nothing matches.
+func NormalizeLevel(raw string) string {
+	lower := strings.ToLower(strings.TrimSpace(raw))
+	if lower == "" {
+		return ""
+	}
+	for _, alias := range levelAliases {
+		if alias.pattern.MatchString(lower) {
+			return alias.level
+		}
+	}
+	return ""
+}
+
+// SeniorityBand collapses a level onto the legacy junior/mid/senior enum.
+func SeniorityBand(level string) string {
+	switch level {
+	case LevelIntern, LevelJunior:
+		return LevelJunior
+	case LevelMid:
+		return LevelMid
+	case LevelSenior, LevelStaff, LevelPrincipal, LevelLead, LevelManager, LevelDirector:
+		return LevelSenior
+	}
+	return ""
+}
+
+// NormalizeEmploymentType maps free-text employment terms onto one of the
+// Employment constants, or "" when nothing matches.
+func NormalizeEmploymentType(raw string) string {
+	lower := strings.ToLower(raw)
+	for _, p := range employmentPatterns {
+		if p.pattern.MatchString(lower) {
+			return p.employment
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package internal_linkedin_scraper
+
+import "testing"
+
+func TestNormalizeLevel(t *testing.T) {
+	tests := []struct{ raw, want string }{
+		{"Software Engineering Intern", LevelIntern},
+		{"New Grad Engineer", LevelJunior},
+		{"Jr. Developer", LevelJunior},
+		{"Mid-level Backend Engineer", LevelMid},
+		{"Senior Go Engineer", LevelSenior},
+		{"Sr Frontend Developer", LevelSenior},
+		{"Senior Staff Engineer", LevelStaff},
+		{"Principal Engineer", LevelPrincipal},
+		{"Tech Lead", LevelLead},
+		{"Lead Backend Engineer", LevelLead},
+		{"Solutions Architect", LevelLead},
+		{"lead", LevelLead},
+		{"Engineering Manager", LevelManager},
+		{"VP of Engineering", LevelDirector},
+		{"Head of Data", LevelDirector},
+		{"Lead Generation Specialist", ""},
+		{"Software Engineer", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLevel(tt.raw); got != tt.want {
+			t.Errorf("NormalizeLevel(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestSeniorityBand(t *testing.T) {
+	tests := []struct{ level, want string }{
+		{LevelIntern, LevelJunior},
+		{LevelJunior, LevelJunior},
+		{LevelMid, LevelMid},
+		{LevelStaff, LevelSenior},
+		{LevelDirector, LevelSenior},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := SeniorityBand(tt.level); got != tt.want {
+			t.Errorf("SeniorityBand(%q) = %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeEmploymentType(t *testing.T) {
+	tests := []struct{ raw, want string }{
+		{"Full-time", EmploymentFullTime},
+		{"FTE, permanent", EmploymentFullTime},
+		{"Part time", EmploymentPartTime},
+		{"Contract (6 months, C2C)", EmploymentContract},
+		{"Freelance", EmploymentContract},
+		{"Summer internship", EmploymentInternship},
+		{"Co-op", EmploymentInternship},
+		{"whatever works", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeEmploymentType(tt.raw); got != tt.want {
+			t.Errorf("NormalizeEmploymentType(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
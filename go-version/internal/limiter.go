@@ -0,0 +1,150 @@
+package internal_linkedin_scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Adaptive concurrency bounds. The limiter starts at maxConcurrentRequests
+// and moves between these as rate-limit headers come back.
+const (
+	minAdaptiveConcurrency = 1
+	maxAdaptiveConcurrency = 16
+
+	// below lowHeadroom of the request or token budget the limiter backs off,
+	// above highHeadroom it lets one more request through
+	lowHeadroom  = 0.1
+	highHeadroom = 0.5
+)
+
+// adaptiveLimiter bounds the number of API calls in flight. Every response
+// is fed to observe, which widens or narrows the bound according to the
+// x-ratelimit-remaining-* headers and pauses all callers when a budget is
+// exhausted. A nil limiter lets everything through.
+type adaptiveLimiter struct {
+	mu          sync.Mutex
+	limit       int
+	min, max    int
+	inFlight    int
+	pausedUntil time.Time
+	wake        chan struct{}
+}
+
+// apiLimiter is shared by every OpenAI call, from Run and the legacy path alike.
+var apiLimiter = newAdaptiveLimiter(maxConcurrentRequests, minAdaptiveConcurrency, maxAdaptiveConcurrency)
+
+func newAdaptiveLimiter(initial, min, max int) *adaptiveLimiter {
+	return &adaptiveLimiter{limit: initial, min: min, max: max, wake: make(chan struct{})}
+}
+
+// acquire blocks until a slot is free and no pause is in effect.
+func (l *adaptiveLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		l.mu.Lock()
+		wait := time.Until(l.pausedUntil)
+		if wait <= 0 && l.inFlight < l.limit {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = time.After(wait)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		case <-timer:
+		}
+	}
+}
+
+func (l *adaptiveLimiter) release() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.broadcast()
+}
+
+// observe adjusts the limit from a response's status and headers.
+func (l *adaptiveLimiter) observe(status int, header http.Header) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if status == http.StatusTooManyRequests {
+		l.setLimit(l.limit / 2)
+		l.pause(retryAfter(header))
+		return
+	}
+
+	headroom, ok := rateLimitHeadroom(header)
+	if !ok {
+		return
+	}
+	switch {
+	case headroom == 0:
+		l.setLimit(l.min)
+		l.pause(retryAfter(header))
+	case headroom < lowHeadroom:
+		l.setLimit(l.limit / 2)
+	case headroom > highHeadroom:
+		l.setLimit(l.limit + 1)
+	}
+}
+
+func (l *adaptiveLimiter) setLimit(limit int) {
+	limit = max(l.min, min(limit, l.max))
+	if limit != l.limit {
+		fmt.Printf("⚖️  Adjusting concurrent requests: %d → %d\n", l.limit, limit)
+		l.limit = limit
+		l.broadcast()
+	}
+}
+
+func (l *adaptiveLimiter) pause(d time.Duration) {
+	if until := time.Now().Add(d); d > 0 && until.After(l.pausedUntil) {
+		fmt.Printf("⏸️  Rate limit budget exhausted, pausing requests for %v\n", d)
+		l.pausedUntil = until
+	}
+}
+
+// broadcast wakes every goroutine blocked in acquire. Callers hold l.mu.
+func (l *adaptiveLimiter) broadcast() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// rateLimitHeadroom returns the smaller of the remaining request and token
+// budgets as a fraction of their limits, or false if the headers are absent.
+func rateLimitHeadroom(header http.Header) (float64, bool) {
+	headroom, ok := 1.0, false
+	for _, kind := range []string{"requests", "tokens"} {
+		limit, errLimit := strconv.Atoi(header.Get("x-ratelimit-limit-" + kind))
+		remaining, errRemaining := strconv.Atoi(header.Get("x-ratelimit-remaining-" + kind))
+		if errLimit != nil || errRemaining != nil || limit <= 0 {
+			continue
+		}
+		ok = true
+		if fraction := float64(remaining) / float64(limit); fraction < headroom {
+			headroom = fraction
+		}
+	}
+	return headroom, ok
+}
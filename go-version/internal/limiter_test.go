@@ -0,0 +1,114 @@
+package internal_linkedin_scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// withHeadroom answers ok with remaining of 100 requests left, resetting
+// after reset.
+func withHeadroom(remaining int, reset string) func(http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("x-ratelimit-limit-requests", "100")
+		w.Header().Set("x-ratelimit-remaining-requests", strconv.Itoa(remaining))
+		w.Header().Set("x-ratelimit-reset-requests", reset)
+		ok(w)
+	}
+}
+
+func TestAdaptiveLimiterFollowsRateLimitHeaders(t *testing.T) {
+	responses := []func(http.ResponseWriter){
+		withHeadroom(60, "1s"),
+		withHeadroom(5, "1s"),
+		withHeadroom(0, "50ms"),
+		withHeadroom(99, "1s"),
+		withHeadroom(99, "1s"),
+	}
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		w.Header().Set("Content-Type", "application/json")
+		responses[min(n, len(responses))-1](w)
+	}))
+	defer server.Close()
+
+	limiter := newAdaptiveLimiter(4, 1, 8)
+	client := newOpenAIClient("test-key", server.URL+"/v1", limiter)
+	send := func() time.Duration {
+		t.Helper()
+		start := time.Now()
+		if _, err := testRetryPolicy.createChatCompletion(context.Background(), client, limiter, chatRequest()); err != nil {
+			t.Fatal(err)
+		}
+		return time.Since(start)
+	}
+
+	for _, step := range []struct {
+		name  string
+		limit int
+	}{
+		{"plenty left", 5},
+		{"under 10% left", 2},
+		{"exhausted", 1},
+	} {
+		send()
+		if limiter.limit != step.limit {
+			t.Errorf("%s: limit = %d, want %d", step.name, limiter.limit, step.limit)
+		}
+	}
+
+	// the next call waits for the reset, then the limit recovers
+	if waited := send(); waited < 40*time.Millisecond {
+		t.Errorf("call after the budget ran out took %v, want it to wait for the 50ms reset", waited)
+	}
+	send()
+	if limiter.limit != 3 {
+		t.Errorf("after the reset: limit = %d, want 3", limiter.limit)
+	}
+}
+
+func TestAdaptiveLimiterBoundsInFlight(t *testing.T) {
+	limiter := newAdaptiveLimiter(2, 1, 4)
+	ctx := context.Background()
+	for range 2 {
+		if err := limiter.acquire(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a third caller blocks until a slot is released
+	acquired := make(chan struct{})
+	go func() {
+		limiter.acquire(ctx)
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired a third slot with a limit of 2")
+	case <-time.After(20 * time.Millisecond):
+	}
+	limiter.release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("release didn't let the waiting caller through")
+	}
+
+	// halving on a 429 never goes below the minimum
+	limiter.observe(http.StatusTooManyRequests, nil)
+	limiter.observe(http.StatusTooManyRequests, nil)
+	if limiter.limit != 1 {
+		t.Errorf("limit = %d after two 429s, want the minimum of 1", limiter.limit)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limiter.acquire(canceled); err == nil {
+		t.Error("acquire with a canceled context succeeded while full")
+	}
+}
@@ -2,24 +2,154 @@ package internal_linkedin_scraper
 
 import (
 	"log"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/sashabaranov/go-openai/jsonschema"
 )
 
 type Listing struct {
-	Title        string `json:"title" jsonschema_description:"The job title"`
-	Location     string `json:"location" jsonschema_description:"Geographic location of the job"`
-	Company      string `json:"company" jsonschema_description:"Company offering the position"`
-	Pay          string `json:"pay" jsonschema_description:"Compensation details for the role"`
-	Technologies string `json:"technologies" jsonschema_description:"Technologies required for the job"`
-	Seniority    string `json:"seniority" jsonschema:"enum=junior,enum=mid,enum=senior" jsonschema_description:"Experience level required"`
-	Description  string `json:"description" jsonschema_description:"Detailed job description"`
-	Contact      string `json:"contact" jsonschema_description:"Contact information, like email, phone, website"`
+	Title          string `json:"title" jsonschema_description:"The job title"`
+	Location       string `json:"location" jsonschema_description:"Geographic location of the job"`
+	Company        string `json:"company" jsonschema_description:"Company offering the position"`
+	Pay            string `json:"pay" jsonschema_description:"Compensation details for the role"`
+	Technologies   string `json:"technologies" jsonschema_description:"Technologies required for the job"`
+	Seniority      string `json:"seniority" enum:",intern,junior,mid,senior,staff,principal,lead,manager,director" jsonschema_description:"Experience level required"`
+	EmploymentType string `json:"employmentType" enum:",full-time,part-time,contract,internship" jsonschema_description:"Type of employment offered"`
+	Description    string `json:"description" jsonschema_description:"Detailed job description"`
+	Contact        string `json:"contact" jsonschema_description:"Contact information, like email, phone, website"`
+
+	// Fields tagged llm:"-" are derived locally after extraction and are
+	// left out of the schema sent to the model.
+	Compensation  Compensation   `json:"compensation" llm:"-"`
+	WorkLocation  WorkLocation   `json:"workLocation" llm:"-"`
+	SeniorityBand string         `json:"seniorityBand" llm:"-"`
+	Contacts      []ContactPoint `json:"contacts" llm:"-"`
+
+	// Confidence holds, per grounding-checked field, how well the value is
+	// supported by the source comment; Ungrounded lists the ones that failed.
+	Confidence map[string]float64 `json:"confidence,omitempty" llm:"-"`
+	Ungrounded []string           `json:"ungrounded,omitempty" llm:"-"`
+
+	// Degraded marks listings built by the rule-based fallback because the
+	// model's output could not be validated.
+	Degraded bool `json:"degraded,omitempty" llm:"-"`
+
+	// CommentID is the HN item id of the comment the listing came from,
+	// Thread the id of the thread it was posted in and Month the month
+	// that thread is for, as "2006-01".
+	CommentID string `json:"commentId,omitempty" llm:"-"`
+	Thread    string `json:"thread,omitempty" llm:"-"`
+	Month     string `json:"month,omitempty" llm:"-"`
+
+	// SourceCommentIDs are the ids of every posting merged into the one
+	// the listing came from, CommentID first, when it had duplicates.
+	SourceCommentIDs []string `json:"sourceCommentIds,omitempty" llm:"-"`
+
+	// PromptVersion names the prompt template and revision that produced
+	// the listing. It is empty for rule-based fallback listings.
+	PromptVersion string `json:"promptVersion,omitempty" llm:"-"`
+
+	// Extra holds the user-defined ExtraFields, keyed by name.
+	Extra map[string]any `json:"extra,omitempty" llm:"-"`
+
+	// Status is open, filled, flagged or deleted, as detected on the
+	// posting and the poster's replies to it.
+	Status string `json:"status,omitempty" llm:"-"`
+
+	// Updates are the poster's own replies to the posting, attached when
+	// AttachUpdates is set.
+	Updates []Update `json:"updates,omitempty" llm:"-"`
+}
+
+// Update is a reply from the poster under their own posting.
+type Update struct {
+	CommentID string    `json:"commentId"`
+	Text      string    `json:"text"`
+	Posted    time.Time `json:"posted"`
+}
+
+// Post is what the model extracts from a single comment: the details shared
+// by every role it advertises, and the roles themselves.
+type Post struct {
+	Company     string     `json:"company" jsonschema_description:"Company offering the positions"`
+	Location    string     `json:"location" jsonschema_description:"Geographic location shared by all positions"`
+	Description string     `json:"description" jsonschema_description:"Detailed job description"`
+	Contact     string     `json:"contact" jsonschema_description:"Contact information, like email, phone, website"`
+	Positions   []Position `json:"positions" jsonschema_description:"Every role advertised in the post"`
+
+	// Extra is filled in when ExtraFields are configured; GetListingSchema
+	// adds its properties at runtime.
+	Extra map[string]any `json:"extra,omitempty" llm:"-"`
+}
+
+// Position is a single role within a Post.
+type Position struct {
+	Title          string `json:"title" jsonschema_description:"The job title"`
+	Location       string `json:"location" jsonschema_description:"Location of this role, if it differs from the post"`
+	Pay            string `json:"pay" jsonschema_description:"Compensation details for the role"`
+	Technologies   string `json:"technologies" jsonschema_description:"Technologies required for the job"`
+	Seniority      string `json:"seniority" enum:",intern,junior,mid,senior,staff,principal,lead,manager,director" jsonschema_description:"Experience level required"`
+	EmploymentType string `json:"employmentType" enum:",full-time,part-time,contract,internship" jsonschema_description:"Type of employment offered"`
+}
+
+// Listings flattens the post into one Listing per position. A post with a
+// company but no positions still yields a single Listing.
+func (p Post) Listings() []Listing {
+	if p.Company == "" && len(p.Positions) == 0 {
+		return nil
+	}
+	positions := p.Positions
+	if len(positions) == 0 {
+		positions = []Position{{}}
+	}
+
+	listings := make([]Listing, 0, len(positions))
+	for _, pos := range positions {
+		l := Listing{
+			Title:          pos.Title,
+			Location:       pos.Location,
+			Company:        p.Company,
+			Pay:            pos.Pay,
+			Technologies:   pos.Technologies,
+			Seniority:      pos.Seniority,
+			EmploymentType: pos.EmploymentType,
+			Description:    p.Description,
+			Contact:        p.Contact,
+			Extra:          p.Extra,
+		}
+		if l.Location == "" {
+			l.Location = p.Location
+		}
+		l.enrich()
+		listings = append(listings, l)
+	}
+	return listings
+}
+
+// enrich fills the derived fields from the raw values returned by the model.
+func (l *Listing) enrich() {
+	l.Compensation = ParsePay(l.Pay)
+	l.WorkLocation = ParseLocation(l.Location)
+	if level := NormalizeLevel(l.Seniority); level != "" {
+		l.Seniority = level
+	} else {
+		l.Seniority = NormalizeLevel(l.Title)
+	}
+	l.SeniorityBand = SeniorityBand(l.Seniority)
+	if l.EmploymentType == "" {
+		l.EmploymentType = NormalizeEmploymentType(l.Title + " " + l.Pay)
+	}
+	l.Contacts = ExtractContacts(l.Contact + "\n" + l.Description)
+	if l.WorkLocation.Visa == "" {
+		l.WorkLocation.Visa = detectVisa(strings.ToLower(l.Description))
+	}
 }
 
 func generateSchema[T any]() *jsonschema.Definition {
 	var listing T
-	schema, err := jsonschema.GenerateSchemaForType(listing)
+	schema, err := jsonschema.GenerateSchemaForType(reflect.Zero(modelFields(reflect.TypeOf(listing))).Interface())
 	if err != nil {
 		log.Fatalf("GenerateSchemaForType error: %v", err)
 	}
@@ -27,6 +157,38 @@ func generateSchema[T any]() *jsonschema.Definition {
 	return schema
 }
 
+// modelFields returns a copy of struct type t without its llm:"-" fields, so
+// derived values, which may be of types the schema can't describe, never
+// reach the model.
+func modelFields(t reflect.Type) reflect.Type {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.Tag.Get("llm") != "-" {
+			fields = append(fields, field)
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+// GetListingSchema returns the schema the model fills in for each comment,
+// including any user-defined ExtraFields.
 func GetListingSchema() *jsonschema.Definition {
-	return generateSchema[Listing]()
+	schema := generateSchema[Post]()
+	if len(ExtraFields) > 0 {
+		schema.Properties["extra"] = extraFieldsSchema(ExtraFields)
+		schema.Required = append(schema.Required, "extra")
+	}
+	return schema
+}
+
+// splitTechnologies splits a free-text technology list, like "Go, AWS and
+// React", into its entries.
+func splitTechnologies(s string) []string {
+	var techs []string
+	for _, tech := range techSplitPattern.Split(s, -1) {
+		if tech = strings.TrimSpace(tech); tech != "" {
+			techs = append(techs, tech)
+		}
+	}
+	return techs
 }
@@ -0,0 +1,68 @@
+package internal_linkedin_scraper
+
+import "testing"
+
+// enriched returns l with its derived fields parsed, as Run stores it.
+func enriched(l Listing) Listing {
+	l.enrich()
+	return l
+}
+
+func TestPostListings(t *testing.T) {
+	type role struct{ title, company, location, contact, pay string }
+	tests := []struct {
+		name string
+		post Post
+		want []role
+	}{
+		{
+			name: "one listing per position, sharing the post's fields",
+			post: Post{
+				Company:  "Acme",
+				Location: "Remote (US)",
+				Contact:  "jobs@acme.example",
+				Positions: []Position{
+					{Title: "Senior Go Engineer", Pay: "$180k"},
+					{Title: "Product Designer", Location: "Toronto", Pay: "$120k"},
+				},
+			},
+			want: []role{
+				{"Senior Go Engineer", "Acme", "Remote (US)", "jobs@acme.example", "$180k"},
+				{"Product Designer", "Acme", "Toronto", "jobs@acme.example", "$120k"},
+			},
+		},
+		{
+			name: "a company without positions still lists",
+			post: Post{Company: "Globex", Location: "Berlin", Contact: "hr@globex.example"},
+			want: []role{{"", "Globex", "Berlin", "hr@globex.example", ""}},
+		},
+		{
+			name: "positions without a company",
+			post: Post{Positions: []Position{{Title: "Firmware Engineer"}}},
+			want: []role{{"Firmware Engineer", "", "", "", ""}},
+		},
+		{
+			name: "nothing to list",
+			post: Post{Description: "We're hiring!"},
+		},
+	}
+	for _, tt := range tests {
+		listings := tt.post.Listings()
+		if len(listings) != len(tt.want) {
+			t.Errorf("%s: got %d listings, want %d", tt.name, len(listings), len(tt.want))
+			continue
+		}
+		for i, l := range listings {
+			got := role{l.Title, l.Company, l.Location, l.Contact, l.Pay}
+			if got != tt.want[i] {
+				t.Errorf("%s: listing %d = %+v, want %+v", tt.name, i, got, tt.want[i])
+			}
+		}
+	}
+
+	// flattened listings are enriched like any other
+	l := Post{Company: "Acme", Location: "Remote", Positions: []Position{{Title: "Senior Go Engineer", Pay: "$180k"}}}.Listings()[0]
+	if l.Compensation.Min != 180000 || !l.WorkLocation.Allows(PolicyRemote) || l.Seniority != LevelSenior {
+		t.Errorf("listing %+v, want pay, location and level parsed", l)
+	}
+}
@@ -0,0 +1,342 @@
+package internal_linkedin_scraper
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// WorkLocation is the structured form of a Listing's free-text Location.
+// Places are where the job is based, Regions restrict where a remote hire may
+// live or work from (countries, continents, timezones).
+type WorkLocation struct {
+	Places   []string `json:"places"`
+	Policies []string `json:"policies"`
+	Regions  []string `json:"regions"`
+	Visa     string   `json:"visa"`
+}
+
+// Remote policies recognised by ParseLocation
+const (
+	PolicyRemote = "remote"
+	PolicyHybrid = "hybrid"
+	PolicyOnsite = "onsite"
+)
+
+// Visa sponsorship values; empty means the post doesn't say
+const (
+	VisaYes = "yes"
+	VisaNo  = "no"
+)
+
+var (
+	policyPatterns = []struct {
+		policy  string
+		pattern *regexp.Regexp
+	}{
+		{PolicyHybrid, regexp.MustCompile(`\bhybrid\b`)},
+		{PolicyOnsite, regexp.MustCompile(`\b(?:on[- ]?site|in[- ]office|in[- ]person|office[- ]based)\b`)},
+		{PolicyRemote, regexp.MustCompile(`\b(?:remote|wfh|distributed|work from home|fully[- ]remote)\b`)},
+	}
+
+	// timezonePattern matches timezones with an unambiguous name or a UTC
+	// offset. The short US names double as state codes and words ("Hartford,
+	// CT", "PT" for part-time, "Est. 2015"), so usTimezonePattern only takes
+	// them with an offset or next to "time" or "hours".
+	timezonePattern   = regexp.MustCompile(`\b(?:utc|gmt|cet|cest|edt|pst|pdt)\s*(?:[+\-−±]\s*\d{1,2}(?::\d{2})?)?(?:\s*(?:to|-|–)\s*(?:utc|gmt)?\s*[+\-−]\s*\d{1,2}(?::\d{2})?)?(?:\s*(?:\+|-|–)\s*\d{1,2}\s*(?:hours?|hrs?|h))?\b`)
+	usTimezonePattern = regexp.MustCompile(`\b(est|et|pt|ct|mt)(?:\s*([+\-−±]\s*\d{1,2}(?::\d{2})?)\b|\s+(?:time|hours?|hrs|tz|time ?zones?)\b)`)
+	noVisaPattern     = regexp.MustCompile(`\b(?:no|not|without|unable to|cannot|can't|can not|don't|do not|won't|will not)\s+(?:\w+\s+){0,2}(?:visa|sponsor)\w*`)
+	visaPattern       = regexp.MustCompile(`\b(?:visa|sponsorship|sponsor|h-?1b)\b[^|;,()\[\]]*`)
+	// sponsorPattern is wording that offers sponsorship, as opposed to
+	// asking for a visa the candidate already holds
+	sponsorPattern = regexp.MustCompile(`\bsponsor\w*|\bh-?1b\s+transfers?\b`)
+	wordPattern    = regexp.MustCompile(`[\p{L}\p{N}]+`)
+	// remoteCulturePattern is remote wording about the team rather than the
+	// role: "onsite in NYC, remote-friendly culture"
+	remoteCulturePattern = regexp.MustCompile(`\bremote(?:[- ](?:friendly|first))?\s+(?:culture|team|teams|company|colleagues|teammates|coworkers)\b`)
+	segmentSplitter      = regexp.MustCompile(`\s*(?:\||;|,|/|&|\+|\bor\b|\band\b)\s*`)
+
+	// cityAliases maps common spellings to a canonical city name
+	cityAliases = map[string]string{
+		"nyc": "New York", "new york": "New York", "new york city": "New York", "ny": "New York", "manhattan": "New York", "brooklyn": "New York",
+		"sf": "San Francisco", "san francisco": "San Francisco", "bay area": "San Francisco", "sf bay area": "San Francisco", "sfba": "San Francisco",
+		"la": "Los Angeles", "los angeles": "Los Angeles", "seattle": "Seattle", "boston": "Boston", "austin": "Austin", "chicago": "Chicago",
+		"denver": "Denver", "boulder": "Boulder", "palo alto": "Palo Alto", "mountain view": "Mountain View", "menlo park": "Menlo Park",
+		"san jose": "San Jose", "oakland": "Oakland", "berkeley": "Berkeley", "san diego": "San Diego", "portland": "Portland",
+		"washington dc": "Washington DC", "dc": "Washington DC", "atlanta": "Atlanta", "miami": "Miami", "pittsburgh": "Pittsburgh",
+		"philadelphia": "Philadelphia", "toronto": "Toronto", "vancouver": "Vancouver", "montreal": "Montreal", "waterloo": "Waterloo",
+		"london": "London", "cambridge": "Cambridge", "oxford": "Oxford", "edinburgh": "Edinburgh", "manchester": "Manchester",
+		"dublin": "Dublin", "berlin": "Berlin", "munich": "Munich", "hamburg": "Hamburg", "amsterdam": "Amsterdam", "paris": "Paris",
+		"zurich": "Zurich", "geneva": "Geneva", "stockholm": "Stockholm", "copenhagen": "Copenhagen", "oslo": "Oslo", "helsinki": "Helsinki",
+		"barcelona": "Barcelona", "madrid": "Madrid", "lisbon": "Lisbon", "warsaw": "Warsaw", "prague": "Prague", "vienna": "Vienna",
+		"tel aviv": "Tel Aviv", "bangalore": "Bangalore", "bengaluru": "Bangalore", "singapore": "Singapore", "tokyo": "Tokyo",
+		"sydney": "Sydney", "melbourne": "Melbourne", "auckland": "Auckland", "sao paulo": "Sao Paulo", "são paulo": "Sao Paulo",
+	}
+
+	// regionAliases maps countries, continents and blocs to a canonical name.
+	// They are places for on-site roles and restrictions for remote ones.
+	regionAliases = map[string]string{
+		"us": "US", "usa": "US", "u.s.": "US", "united states": "US", "america": "US",
+		"canada": "Canada", "mexico": "Mexico", "uk": "UK", "united kingdom": "UK", "england": "UK",
+		"ireland": "Ireland", "germany": "Germany", "france": "France", "netherlands": "Netherlands",
+		"spain": "Spain", "portugal": "Portugal", "italy": "Italy", "poland": "Poland", "sweden": "Sweden", "norway": "Norway",
+		"denmark": "Denmark", "finland": "Finland", "switzerland": "Switzerland", "austria": "Austria", "israel": "Israel",
+		"india": "India", "japan": "Japan", "australia": "Australia", "new zealand": "New Zealand", "brazil": "Brazil",
+		"eu": "EU", "europe": "Europe", "emea": "EMEA", "apac": "APAC", "asia": "Asia", "americas": "Americas",
+		"north america": "North America", "latam": "LATAM", "latin america": "LATAM", "south america": "LATAM",
+		"worldwide": "Worldwide", "global": "Worldwide", "anywhere": "Worldwide", "international": "Worldwide",
+	}
+
+	// locationNoise are words that qualify a place without naming one
+	locationNoise = []string{
+		"only", "based", "preferred", "required", "friendly", "timezones", "timezone", "time zones", "time zone", "tz",
+		"hours", "overlap", "within", "from", "in", "the", "office", "offices", "or", "and", "days", "day", "week",
+		"per", "a", "optional", "ok", "possible", "welcome", "full time", "full-time", "residents", "citizens", "area",
+		"must", "with", "be", "at", "least", "core", "business", "working", "work", "east", "west", "coast",
+		"part time", "part-time", "contract", "contractor", "freelance", "internship",
+		// US state codes that follow a city
+		"al", "ak", "az", "ar", "ca", "co", "ct", "fl", "ga", "il", "ma", "md", "mi", "mn", "nc", "nj", "oh", "or", "pa", "tn", "tx", "ut", "va", "wa", "wi",
+	}
+	locationNoisePattern = regexp.MustCompile(`\b(?:` + strings.Join(locationNoise, "|") + `)\b|\d+`)
+)
+
+// ParseLocation turns free-text locations such as "REMOTE (US only)",
+// "ONSITE/HYBRID", "NYC or SF" or "Remote EU timezones" into a WorkLocation.
+func ParseLocation(raw string) WorkLocation {
+	var w WorkLocation
+	lower := strings.ToLower(raw)
+	if strings.TrimSpace(lower) == "" {
+		return w
+	}
+
+	lower = remoteCulturePattern.ReplaceAllString(lower, " ")
+	w.Visa = detectVisa(lower)
+	lower = visaPattern.ReplaceAllString(noVisaPattern.ReplaceAllString(lower, " "), " ")
+
+	for _, tz := range timezonePattern.FindAllString(lower, -1) {
+		w.Regions = appendUnique(w.Regions, strings.ReplaceAll(strings.ToUpper(strings.Join(strings.Fields(tz), " ")), " TO ", " to "))
+	}
+	lower = timezonePattern.ReplaceAllString(lower, " ")
+	for _, m := range usTimezonePattern.FindAllStringSubmatch(lower, -1) {
+		w.Regions = appendUnique(w.Regions, strings.ToUpper(m[1]+strings.Join(strings.Fields(m[2]), "")))
+	}
+	lower = usTimezonePattern.ReplaceAllString(lower, " ")
+
+	// names the poster capitalized, the only unknown words taken as places
+	capitalized := make(map[string]bool)
+	for _, word := range wordPattern.FindAllString(raw, -1) {
+		if unicode.IsUpper([]rune(word)[0]) {
+			capitalized[strings.ToLower(word)] = true
+		}
+	}
+
+	// Text in parentheses qualifies the segment before it, so "Remote (US)"
+	// restricts a remote role to the US rather than naming an office there.
+	var inherited []string
+	depth := 0
+	for _, group := range splitParens(lower) {
+		if group.depth < depth || group.depth == 0 {
+			inherited = nil
+		}
+		depth = group.depth
+
+		var context []string
+		for _, segment := range segmentSplitter.Split(group.text, -1) {
+			segment, policies := extractPolicies(segment)
+			for _, p := range policies {
+				w.Policies = appendUnique(w.Policies, p)
+			}
+			if len(policies) > 0 {
+				context = policies
+			} else if context == nil {
+				context = inherited
+			}
+			remote := slices.Contains(context, PolicyRemote) && len(context) == 1
+			for _, place := range extractPlaces(segment, capitalized) {
+				if _, isCity := cityByName(strings.ToLower(place)); remote && !isCity {
+					w.Regions = appendUnique(w.Regions, place)
+				} else {
+					w.Places = appendUnique(w.Places, place)
+				}
+			}
+		}
+		if context != nil {
+			inherited = context
+		}
+	}
+
+	return w
+}
+
+// Allows reports whether the listing can be worked under policy. A listing
+// that doesn't state any policy allows none.
+func (w WorkLocation) Allows(policy string) bool {
+	return slices.Contains(w.Policies, policy)
+}
+
+// Mentions reports whether place matches one of the listing's places or
+// regions, case-insensitively.
+func (w WorkLocation) Mentions(place string) bool {
+	if canonical, ok := cityByName(strings.ToLower(place)); ok {
+		place = canonical
+	} else if canonical, ok := regionAliases[strings.ToLower(place)]; ok {
+		place = canonical
+	}
+	for _, p := range append(slices.Clone(w.Places), w.Regions...) {
+		if strings.EqualFold(p, place) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByPolicy keeps listings that can be worked under policy.
+func FilterByPolicy(listings []Listing, policy string) []Listing {
+	var filtered []Listing
+	for _, l := range listings {
+		if l.WorkLocation.Allows(policy) {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
+}
+
+// FilterByPlace keeps listings based in or open to place.
+func FilterByPlace(listings []Listing, place string) []Listing {
+	var filtered []Listing
+	for _, l := range listings {
+		if l.WorkLocation.Mentions(place) {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
+}
+
+func detectVisa(lower string) string {
+	switch {
+	case noVisaPattern.MatchString(lower):
+		return VisaNo
+	case sponsorPattern.MatchString(lower):
+		return VisaYes
+	}
+	return ""
+}
+
+type parenGroup struct {
+	text  string
+	depth int
+}
+
+// splitParens breaks s into runs of text with their parenthesis depth.
+func splitParens(s string) []parenGroup {
+	var groups []parenGroup
+	var current strings.Builder
+	depth := 0
+	flush := func() {
+		if strings.TrimSpace(current.String()) != "" {
+			groups = append(groups, parenGroup{text: current.String(), depth: depth})
+		}
+		current.Reset()
+	}
+	for _, r := range s {
+		switch r {
+		case '(', '[':
+			flush()
+			depth++
+		case ')', ']':
+			flush()
+			if depth > 0 {
+				depth--
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return groups
+}
+
+func extractPolicies(segment string) (string, []string) {
+	var policies []string
+	for _, p := range policyPatterns {
+		if p.pattern.MatchString(segment) {
+			policies = append(policies, p.policy)
+			segment = p.pattern.ReplaceAllString(segment, " ")
+		}
+	}
+	return segment, policies
+}
+
+// extractPlaces returns the canonical names of places in segment. When no
+// known place is found, a short run of words the poster capitalized is kept
+// title-cased so that small towns aren't lost; other words are prose, like
+// "must hold a valid visa", not places.
+func extractPlaces(segment string, capitalized map[string]bool) []string {
+	segment = strings.Trim(strings.Join(strings.Fields(segment), " "), " .-:")
+	if segment == "" {
+		return nil
+	}
+	if city, ok := cityByName(segment); ok {
+		return []string{city}
+	}
+	if region, ok := regionAliases[segment]; ok {
+		return []string{region}
+	}
+
+	// try each word on its own, e.g. "eu timezones" or "london uk"
+	cleaned := strings.Join(strings.Fields(locationNoisePattern.ReplaceAllString(segment, " ")), " ")
+	if cleaned == "" {
+		return nil
+	}
+	if city, ok := cityByName(cleaned); ok {
+		return []string{city}
+	}
+	if region, ok := regionAliases[cleaned]; ok {
+		return []string{region}
+	}
+	var places []string
+	words := strings.Fields(cleaned)
+	for _, word := range words {
+		if city, ok := cityByName(word); ok {
+			places = append(places, city)
+		} else if region, ok := regionAliases[word]; ok {
+			places = append(places, region)
+		}
+	}
+	if len(places) > 0 {
+		return places
+	}
+	words = wordPattern.FindAllString(cleaned, -1)
+	name := strings.Join(words, " ")
+	if len(words) == 0 || len(words) > 3 || len([]rune(name)) < 4 {
+		return nil
+	}
+	for _, word := range words {
+		if !capitalized[word] {
+			return nil
+		}
+	}
+	return []string{titleCase(name)}
+}
+
+func cityByName(name string) (string, bool) {
+	city, ok := cityAliases[name]
+	return city, ok
+}
+
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		r := []rune(w)
+		words[i] = string(unicode.ToUpper(r[0])) + string(r[1:])
+	}
+	return strings.Join(words, " ")
+}
+
+func appendUnique(list []string, value string) []string {
+	if value == "" || slices.Contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
@@ -0,0 +1,65 @@
+package internal_linkedin_scraper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want WorkLocation
+	}{
+		{"REMOTE (US only)", WorkLocation{Policies: []string{PolicyRemote}, Regions: []string{"US"}}},
+		{"ONSITE/HYBRID", WorkLocation{Policies: []string{PolicyOnsite, PolicyHybrid}}},
+		{"NYC or SF", WorkLocation{Places: []string{"New York", "San Francisco"}}},
+		{"Remote EU timezones", WorkLocation{Policies: []string{PolicyRemote}, Regions: []string{"EU"}}},
+		// a city next to remote is an office, not a restriction
+		{"Remote or NYC", WorkLocation{Places: []string{"New York"}, Policies: []string{PolicyRemote}}},
+		{"Hybrid (London, 2 days/week)", WorkLocation{Places: []string{"London"}, Policies: []string{PolicyHybrid}}},
+		{"San Francisco, CA | Remote (US/Canada)", WorkLocation{Places: []string{"San Francisco"}, Policies: []string{PolicyRemote}, Regions: []string{"US", "Canada"}}},
+		{"Remote (UTC-3 to UTC+3), no visa sponsorship", WorkLocation{Policies: []string{PolicyRemote}, Regions: []string{"UTC-3 to UTC+3"}, Visa: VisaNo}},
+		{"Onsite in Berlin; visa sponsorship available", WorkLocation{Places: []string{"Berlin"}, Policies: []string{PolicyOnsite}, Visa: VisaYes}},
+		// state codes and words that look like timezones
+		{"Hartford, CT", WorkLocation{Places: []string{"Hartford"}}},
+		{"Part-time remote, PT hours", WorkLocation{Policies: []string{PolicyRemote}, Regions: []string{"PT"}}},
+		{"Est. 2015, Austin", WorkLocation{Places: []string{"Austin"}}},
+		{"Remote (EST+2)", WorkLocation{Policies: []string{PolicyRemote}, Regions: []string{"EST+2"}}},
+		// holding a visa isn't being sponsored for one, and prose isn't a place
+		{"Must hold a valid visa", WorkLocation{}},
+		{"must be authorized to work in the US", WorkLocation{Places: []string{"US"}}},
+		{"Onsite in NYC, remote-friendly culture", WorkLocation{Places: []string{"New York"}, Policies: []string{PolicyOnsite}}},
+		{"", WorkLocation{}},
+	}
+	for _, tt := range tests {
+		if got := ParseLocation(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseLocation(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFilterByPolicyAndPlace(t *testing.T) {
+	listings := []Listing{
+		{Company: "remote-us", WorkLocation: ParseLocation("Remote (US only)")},
+		{Company: "nyc", WorkLocation: ParseLocation("NYC, onsite")},
+		{Company: "remote-or-nyc", WorkLocation: ParseLocation("Remote or NYC")},
+		{Company: "unstated"},
+	}
+	companies := func(ls []Listing) []string {
+		var names []string
+		for _, l := range ls {
+			names = append(names, l.Company)
+		}
+		return names
+	}
+
+	if got := companies(FilterByPolicy(listings, PolicyRemote)); !reflect.DeepEqual(got, []string{"remote-us", "remote-or-nyc"}) {
+		t.Errorf("remote listings %v", got)
+	}
+	if got := companies(FilterByPlace(listings, "new york city")); !reflect.DeepEqual(got, []string{"nyc", "remote-or-nyc"}) {
+		t.Errorf("New York listings %v", got)
+	}
+	if got := companies(FilterByPlace(listings, "USA")); !reflect.DeepEqual(got, []string{"remote-us"}) {
+		t.Errorf("listings open to the US %v", got)
+	}
+}
@@ -0,0 +1,119 @@
+package internal_linkedin_scraper
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+var (
+	// openAIBaseURL overrides the API endpoint when set, e.g. to point at a
+	// local fake server.
+	openAIBaseURL = ""
+
+	clientMu sync.Mutex
+)
+
+// headerSink receives the headers of the response to the request whose
+// context carries it. go-openai doesn't expose headers on errors, and the
+// retry policy needs Retry-After from exactly those responses.
+type headerSink struct {
+	mu     sync.Mutex
+	header http.Header
+}
+
+type headerSinkKey struct{}
+
+func withHeaderSink(ctx context.Context) (context.Context, *headerSink) {
+	sink := &headerSink{}
+	return context.WithValue(ctx, headerSinkKey{}, sink), sink
+}
+
+func (s *headerSink) get() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.header
+}
+
+// capturingDoer is the HTTP client handed to go-openai. It copies each
+// response's headers into the request's headerSink, if it has one, and
+// reports them to the limiter.
+type capturingDoer struct {
+	client  *http.Client
+	limiter *adaptiveLimiter
+}
+
+func (d capturingDoer) Do(req *http.Request) (*http.Response, error) {
+	res, err := d.client.Do(req)
+	if err != nil {
+		return res, err
+	}
+	if sink, ok := req.Context().Value(headerSinkKey{}).(*headerSink); ok {
+		sink.mu.Lock()
+		sink.header = res.Header.Clone()
+		sink.mu.Unlock()
+	}
+	d.limiter.observe(res.StatusCode, res.Header)
+	return res, nil
+}
+
+// newOpenAIClient builds a client whose responses can be inspected by the
+// retry policy and, if limiter is non-nil, drive its concurrency.
+func newOpenAIClient(key, baseURL string, limiter *adaptiveLimiter) *gpt.Client {
+	config := gpt.DefaultConfig(key)
+	if baseURL != "" {
+		config.BaseURL = baseURL
+	}
+	config.HTTPClient = capturingDoer{client: httpClient, limiter: limiter}
+	return gpt.NewClientWithConfig(config)
+}
+
+// SetOpenAIBaseURL points the shared client at another OpenAI-compatible
+// endpoint.
+func SetOpenAIBaseURL(url string) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	openAIBaseURL = url
+	oaiclient = nil
+}
+
+// getOpenAIClient returns the client shared by Run and the legacy scraper,
+// creating it from OPENAI_KEY on first use.
+func getOpenAIClient() *gpt.Client {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if oaiclient == nil {
+		oaiclient = newOpenAIClient(os.Getenv("OPENAI_KEY"), openAIBaseURL, apiLimiter)
+	}
+	return oaiclient
+}
+
+// createChatCompletion sends req with the shared client, throttled by
+// apiLimiter and retrying rate limits and transient server errors according
+// to defaultRetryPolicy. Requests that don't fit in the run's budget fail
+// with ErrBudgetExceeded without being sent. Usage is recorded in runStats.
+// Responses are cached on disk, and a cached answer costs nothing.
+func createChatCompletion(ctx context.Context, req gpt.ChatCompletionRequest) (gpt.ChatCompletionResponse, error) {
+	if resp, ok := loadCachedResponse(req); ok {
+		runStats.recordCacheHit()
+		return resp, nil
+	}
+	reserved, err := budget.reserve(req.Model, countRequestTokens(req))
+	if err != nil {
+		return gpt.ChatCompletionResponse{}, err
+	}
+	resp, err := defaultRetryPolicy.createChatCompletion(ctx, getOpenAIClient(), apiLimiter, req)
+	if err != nil {
+		budget.release(reserved)
+		runStats.recordFailure()
+		return resp, err
+	}
+	cost := estimateCost(req.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	budget.spend(reserved, resp.Usage.TotalTokens, cost)
+	runStats.recordUsage(req.Model, resp.Usage, cost)
+	storeCachedResponse(req, resp)
+	return resp, nil
+}
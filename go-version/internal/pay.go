@@ -0,0 +1,261 @@
+package internal_linkedin_scraper
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Compensation is the structured form of a Listing's free-text Pay.
+// Min and Max are in whole currency units for the given Period. A single
+// figure sets both; an open-ended figure like "150k+" leaves Max at zero,
+// and an upper bound like "up to $200k" leaves Min at zero.
+type Compensation struct {
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Currency string  `json:"currency"`
+	Period   string  `json:"period"`
+	Equity   bool    `json:"equity"`
+}
+
+// Pay periods recognised by ParsePay
+const (
+	PeriodHourly  = "hourly"
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+	PeriodAnnual  = "annual"
+)
+
+var (
+	// amountPattern matches a figure with optional thousands separators and k/m suffix
+	amountPattern     = regexp.MustCompile(`(\d+(?:[.,'’]\d+)*)\s*(?:([km])\b)?(\+)?`)
+	upToPattern       = regexp.MustCompile(`\b(?:up to|max(?:imum)?|<)\s*\D{0,4}$`)
+	rangeSepPattern   = regexp.MustCompile(`^\s*[^\d\s]{0,4}\s*(?:-|–|—|to)\s*[^\d\s]{0,4}\s*$`)
+	experiencePattern = regexp.MustCompile(`^\+?\s*(?:years?|yrs?|yoe)\b`)
+	currencyPattern   = regexp.MustCompile(`\b(usd|eur|gbp|cad|aud|nzd|chf|sek|nok|dkk|pln|jpy|inr|sgd)\b`)
+
+	// multi-character symbols must be checked before "$"
+	currencySymbols = []struct{ symbol, code string }{
+		{"us$", "USD"}, {"ca$", "CAD"}, {"c$", "CAD"}, {"au$", "AUD"}, {"a$", "AUD"}, {"nz$", "NZD"}, {"s$", "SGD"},
+		{"$", "USD"}, {"€", "EUR"}, {"£", "GBP"}, {"¥", "JPY"}, {"₹", "INR"},
+	}
+
+	periodMarkers = []struct {
+		period  string
+		markers []string
+	}{
+		{PeriodHourly, []string{"/hr", "/hour", "/h ", "per hour", "an hour", "hourly", "p/h"}},
+		{PeriodDaily, []string{"/day", "/d ", "per day", "a day", "daily", "day rate", "p/d"}},
+		{PeriodMonthly, []string{"/mo", "/month", "per month", "a month", "monthly", "p/m"}},
+		{PeriodAnnual, []string{"/yr", "/year", "/y ", "per year", "per annum", "a year", "annual", "yearly", "p/a", "p.a.", " pa ", " ote", "salary"}},
+	}
+
+	equityMarkers = []string{"equity", "stock", "options", "rsu", "esop", "shares"}
+)
+
+// ParsePay turns free-text compensation such as "$150k-$200k", "€80.000",
+// "120-150K USD + equity" or "£500/day" into a Compensation. Anything it
+// cannot make sense of is left at its zero value.
+func ParsePay(raw string) Compensation {
+	var c Compensation
+	lower := strings.ToLower(raw)
+	if strings.TrimSpace(lower) == "" {
+		return c
+	}
+
+	for _, marker := range equityMarkers {
+		if strings.Contains(lower, marker) {
+			c.Equity = true
+			break
+		}
+	}
+	c.Currency = detectCurrency(lower)
+
+	amounts, openEnded, upTo := parseAmounts(lower)
+	switch len(amounts) {
+	case 0:
+	case 1:
+		if !upTo {
+			c.Min = amounts[0]
+		}
+		if !openEnded {
+			c.Max = amounts[0]
+		}
+	default:
+		c.Min, c.Max = amounts[0], amounts[1]
+		if c.Min > c.Max {
+			c.Min, c.Max = c.Max, c.Min
+		}
+	}
+
+	c.Period = detectPeriod(lower + " ")
+	if c.Period == "" && max(c.Min, c.Max) >= 10000 {
+		c.Period = PeriodAnnual
+	}
+
+	return c
+}
+
+// IsZero reports whether no figure could be parsed.
+func (c Compensation) IsZero() bool {
+	return c.Min == 0 && c.Max == 0
+}
+
+// Annualized returns Min and Max scaled to a yearly figure so listings paid
+// by the hour, day or month can be compared. Currencies are not converted.
+func (c Compensation) Annualized() (float64, float64) {
+	factor := 1.0
+	switch c.Period {
+	case PeriodHourly:
+		factor = 2080
+	case PeriodDaily:
+		factor = 260
+	case PeriodMonthly:
+		factor = 12
+	}
+	return c.Min * factor, c.Max * factor
+}
+
+// SortByPay orders listings by annualized pay, highest first. Listings
+// without parsed pay sort last.
+func SortByPay(listings []Listing) {
+	sort.SliceStable(listings, func(i, j int) bool {
+		return payKey(listings[i].Compensation) > payKey(listings[j].Compensation)
+	})
+}
+
+func payKey(c Compensation) float64 {
+	lo, hi := c.Annualized()
+	if hi > 0 {
+		return hi
+	}
+	return lo
+}
+
+// FilterByPay keeps listings whose annualized pay can reach at least minAnnual.
+func FilterByPay(listings []Listing, minAnnual float64) []Listing {
+	var filtered []Listing
+	for _, l := range listings {
+		if payKey(l.Compensation) >= minAnnual {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
+}
+
+func detectCurrency(lower string) string {
+	if code := currencyPattern.FindString(lower); code != "" {
+		return strings.ToUpper(code)
+	}
+	for _, s := range currencySymbols {
+		if strings.Contains(lower, s.symbol) {
+			return s.code
+		}
+	}
+	return ""
+}
+
+func detectPeriod(lower string) string {
+	for _, p := range periodMarkers {
+		for _, marker := range p.markers {
+			if strings.Contains(lower, marker) {
+				return p.period
+			}
+		}
+	}
+	return ""
+}
+
+// parseAmounts returns the first one or two money figures in lower, scaled
+// by their k/m suffix, whether the last one was open-ended ("150k+") and
+// whether a single figure is only an upper bound ("up to $200k").
+func parseAmounts(lower string) ([]float64, bool, bool) {
+	type figure struct {
+		value   float64
+		suffix  string
+		openEnd bool
+		start   int
+		end     int
+	}
+	var figures []figure
+	for _, m := range amountPattern.FindAllStringSubmatchIndex(lower, -1) {
+		rest := lower[m[1]:]
+		if strings.HasPrefix(rest, "%") || experiencePattern.MatchString(rest) {
+			continue
+		}
+		value, ok := parseNumber(lower[m[2]:m[3]])
+		if !ok {
+			continue
+		}
+		f := figure{value: value, start: m[0], end: m[1]}
+		if m[4] >= 0 {
+			f.suffix = lower[m[4]:m[5]]
+		}
+		f.openEnd = m[6] >= 0
+		figures = append(figures, f)
+	}
+	if len(figures) == 0 {
+		return nil, false, false
+	}
+
+	scale := func(f figure) float64 {
+		switch f.suffix {
+		case "k":
+			return f.value * 1000
+		case "m":
+			return f.value * 1000000
+		}
+		return f.value
+	}
+
+	first := figures[0]
+	if len(figures) > 1 && rangeSepPattern.MatchString(lower[first.end:figures[1].start]) {
+		second := figures[1]
+		// "120-150K": the suffix on the upper bound applies to both
+		if first.suffix == "" && second.suffix != "" && first.value <= second.value {
+			first.suffix = second.suffix
+		}
+		return []float64{scale(first), scale(second)}, second.openEnd, false
+	}
+	return []float64{scale(first)}, first.openEnd, upToPattern.MatchString(lower[:first.start])
+}
+
+// parseNumber reads a figure using either "," or "." as the thousands
+// separator, e.g. "120,000", "80.000", "1.5" or "1.234,56". Apostrophes
+// are always thousands separators, as in the Swiss "120'000".
+func parseNumber(s string) (float64, bool) {
+	s = strings.NewReplacer("'", "", "’", "").Replace(s)
+	lastComma, lastDot := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	var decimal byte
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		if lastComma > lastDot {
+			decimal = ','
+		} else {
+			decimal = '.'
+		}
+	case lastComma >= 0 || lastDot >= 0:
+		sep := lastComma
+		if lastDot >= 0 {
+			sep = lastDot
+		}
+		// a separator followed by exactly three digits is a thousands separator
+		if len(s)-sep-1 != 3 {
+			decimal = s[sep]
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == decimal:
+			b.WriteByte('.')
+		case s[i] == ',' || s[i] == '.':
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	value, err := strconv.ParseFloat(b.String(), 64)
+	return value, err == nil
+}
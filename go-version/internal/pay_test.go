@@ -0,0 +1,46 @@
+package internal_linkedin_scraper
+
+import "testing"
+
+func TestParsePay(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Compensation
+	}{
+		{"$150k-$200k", Compensation{Min: 150000, Max: 200000, Currency: "USD", Period: PeriodAnnual}},
+		{"€80.000", Compensation{Min: 80000, Max: 80000, Currency: "EUR", Period: PeriodAnnual}},
+		{"120-150K USD + equity", Compensation{Min: 120000, Max: 150000, Currency: "USD", Period: PeriodAnnual, Equity: true}},
+		{"£500/day", Compensation{Min: 500, Max: 500, Currency: "GBP", Period: PeriodDaily}},
+		{"$80-100/hr", Compensation{Min: 80, Max: 100, Currency: "USD", Period: PeriodHourly}},
+		{"CHF 120'000 - 140'000", Compensation{Min: 120000, Max: 140000, Currency: "CHF", Period: PeriodAnnual}},
+		{"120,000 to 140,000 CAD", Compensation{Min: 120000, Max: 140000, Currency: "CAD", Period: PeriodAnnual}},
+		{"€1.234,56 per month", Compensation{Min: 1234.56, Max: 1234.56, Currency: "EUR", Period: PeriodMonthly}},
+		{"$180k+ and stock options", Compensation{Min: 180000, Currency: "USD", Period: PeriodAnnual, Equity: true}},
+		{"up to $200k", Compensation{Max: 200000, Currency: "USD", Period: PeriodAnnual}},
+		{"5+ years experience, $160k", Compensation{Min: 160000, Max: 160000, Currency: "USD", Period: PeriodAnnual}},
+		{"US$150k-200k", Compensation{Min: 150000, Max: 200000, Currency: "USD", Period: PeriodAnnual}},
+		{"150.000€ - 180.000€", Compensation{Min: 150000, Max: 180000, Currency: "EUR", Period: PeriodAnnual}},
+		{"competitive", Compensation{}},
+		{"", Compensation{}},
+	}
+	for _, tt := range tests {
+		if got := ParsePay(tt.raw); got != tt.want {
+			t.Errorf("ParsePay(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFilterAndSortByPay(t *testing.T) {
+	listings := []Listing{
+		{Company: "hourly", Compensation: ParsePay("$90/hr")},
+		{Company: "none"},
+		{Company: "annual", Compensation: ParsePay("$150k-$170k")},
+	}
+	SortByPay(listings)
+	if listings[0].Company != "hourly" || listings[2].Company != "none" {
+		t.Errorf("sorted %v, want annualized hourly pay first and no pay last", listings)
+	}
+	if got := FilterByPay(listings, 160000); len(got) != 2 {
+		t.Errorf("FilterByPay kept %d listings, want 2", len(got))
+	}
+}
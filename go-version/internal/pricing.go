@@ -0,0 +1,85 @@
+package internal_linkedin_scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sync"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+// modelPrice is what a model costs per million tokens, in US dollars.
+type modelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// modelPrices holds the list prices at the time of writing. LoadPricing
+// overrides them from config so a price change doesn't need a rebuild.
+var modelPrices = map[string]modelPrice{
+	gpt.GPT4:          {Input: 30, Output: 60},
+	gpt.GPT4o:         {Input: 2.5, Output: 10},
+	gpt.GPT4oMini:     {Input: 0.15, Output: 0.6},
+	gpt.GPT4Dot1:      {Input: 2, Output: 8},
+	gpt.GPT3Dot5Turbo: {Input: 0.5, Output: 1.5},
+}
+
+// LoadPricing merges the price table at path, a JSON object of model name
+// to {"input": ..., "output": ...} dollars per million tokens, over the
+// built-in prices. A missing file leaves the defaults in place.
+func LoadPricing(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var prices map[string]modelPrice
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for model, price := range prices {
+		modelPrices[model] = price
+	}
+	return nil
+}
+
+// snapshotSuffix matches the date a pinned model snapshot is named with,
+// as in gpt-4o-mini-2024-07-18 or gpt-3.5-turbo-0125.
+var snapshotSuffix = regexp.MustCompile(`-(?:\d{4}-\d{2}-\d{2}|\d{4})$`)
+
+// unpricedModels remembers the models already warned about, so a run logs
+// each one once.
+var unpricedModels sync.Map
+
+// priceOf looks model up in the price table, falling back to the model a
+// dated snapshot belongs to. A model that still isn't found is logged and
+// charged the highest price in the table, so the budget errs on the side of
+// stopping early rather than running unchecked.
+func priceOf(model string) modelPrice {
+	if price, ok := modelPrices[model]; ok {
+		return price
+	}
+	if price, ok := modelPrices[snapshotSuffix.ReplaceAllString(model, "")]; ok {
+		return price
+	}
+	if _, warned := unpricedModels.LoadOrStore(model, true); !warned {
+		log.Printf("[WARN] No price configured for model %s, estimating its cost at the highest known price", model)
+	}
+	var highest modelPrice
+	for _, price := range modelPrices {
+		highest.Input = max(highest.Input, price.Input)
+		highest.Output = max(highest.Output, price.Output)
+	}
+	return highest
+}
+
+// estimateCost returns the price in dollars of a call to model.
+func estimateCost(model string, promptTokens, completionTokens int) float64 {
+	price := priceOf(model)
+	return (float64(promptTokens)*price.Input + float64(completionTokens)*price.Output) / 1e6
+}
@@ -0,0 +1,72 @@
+package internal_linkedin_scraper
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+// usePrices swaps in a copy of the price table, restoring it when the test
+// ends.
+func usePrices(t *testing.T) {
+	t.Helper()
+	prev := modelPrices
+	t.Cleanup(func() { modelPrices = prev })
+	modelPrices = maps.Clone(prev)
+}
+
+func TestLoadPricing(t *testing.T) {
+	usePrices(t)
+	dir := t.TempDir()
+
+	if err := LoadPricing(filepath.Join(dir, "missing.json")); err != nil {
+		t.Errorf("missing file: %v, want the defaults kept", err)
+	}
+
+	path := filepath.Join(dir, "pricing.json")
+	os.WriteFile(path, []byte(`{"gpt-4o-mini": {"input": 1, "output": 2}, "my-model": {"input": 3, "output": 4}}`), 0644)
+	if err := LoadPricing(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := modelPrices[gpt.GPT4oMini]; got != (modelPrice{Input: 1, Output: 2}) {
+		t.Errorf("gpt-4o-mini = %+v, want the override", got)
+	}
+	if got := modelPrices["my-model"]; got != (modelPrice{Input: 3, Output: 4}) {
+		t.Errorf("my-model = %+v, want it added", got)
+	}
+	if got := modelPrices[gpt.GPT4o]; got != (modelPrice{Input: 2.5, Output: 10}) {
+		t.Errorf("gpt-4o = %+v, want the default kept", got)
+	}
+
+	os.WriteFile(path, []byte(`{"gpt-4o-mini": 1}`), 0644)
+	if err := LoadPricing(path); err == nil {
+		t.Error("malformed file loaded without an error")
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	usePrices(t)
+	modelPrices = map[string]modelPrice{
+		"cheap":  {Input: 1, Output: 2},
+		"pricey": {Input: 10, Output: 20},
+	}
+
+	for _, c := range []struct {
+		model string
+		want  float64
+	}{
+		{"cheap", 1 + 2},
+		{"cheap-2024-07-18", 1 + 2},
+		{"cheap-0125", 1 + 2},
+		// unknown models are charged the highest price, not nothing
+		{"mystery", 10 + 20},
+		{"mystery-2025-01-01", 10 + 20},
+	} {
+		if got := estimateCost(c.model, 1e6, 1e6); got != c.want {
+			t.Errorf("estimateCost(%q) = %v, want %v", c.model, got, c.want)
+		}
+	}
+}
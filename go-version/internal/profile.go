@@ -0,0 +1,319 @@
+package internal_linkedin_scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is what a candidate looks for in a job. Listings are scored
+// against it by Score instead of being dropped by the model.
+type Profile struct {
+	Skills []Skill `json:"skills" yaml:"skills"`
+	// MinPay is the lowest acceptable annual pay, in Currency
+	MinPay   float64 `json:"minPay" yaml:"minPay"`
+	Currency string  `json:"currency" yaml:"currency"`
+	// Locations are the places the candidate can work in
+	Locations []string `json:"locations" yaml:"locations"`
+	// Remote is required, preferred or any
+	Remote string `json:"remote" yaml:"remote"`
+	// Exclude are technologies that rule a listing out
+	Exclude []string `json:"exclude" yaml:"exclude"`
+}
+
+// Skill is a technology the candidate knows. Weight is how much a listing
+// asking for it counts, 1 if unset, and Aliases other names it goes by.
+type Skill struct {
+	Name    string   `json:"name" yaml:"name"`
+	Weight  float64  `json:"weight" yaml:"weight"`
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+}
+
+// Remote preferences
+const (
+	RemoteRequired  = "required"
+	RemotePreferred = "preferred"
+	RemoteAny       = "any"
+)
+
+// The points each criterion is worth. A listing's score is the share of
+// the points for the criteria the profile sets, out of 100.
+const (
+	skillPoints    = 50.0
+	payPoints      = 20.0
+	locationPoints = 15.0
+	remotePoints   = 15.0
+)
+
+// LoadProfile reads a profile from a YAML or JSON file, picked by its
+// extension.
+func LoadProfile(path string) (Profile, error) {
+	var p Profile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &p)
+	default:
+		err = json.Unmarshal(data, &p)
+	}
+	if err != nil {
+		return p, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for i, skill := range p.Skills {
+		if strings.TrimSpace(skill.Name) == "" {
+			return p, fmt.Errorf("%s: skill %d has no name", path, i+1)
+		}
+		if skill.Weight < 0 {
+			return p, fmt.Errorf("%s: skill %q has a negative weight", path, skill.Name)
+		}
+		if skill.Weight == 0 {
+			p.Skills[i].Weight = 1
+		}
+	}
+	switch p.Remote {
+	case "":
+		p.Remote = RemoteAny
+	case RemoteRequired, RemotePreferred, RemoteAny:
+	default:
+		return p, fmt.Errorf("%s: remote must be %s, %s or %s, not %q", path, RemoteRequired, RemotePreferred, RemoteAny, p.Remote)
+	}
+	if p.Currency == "" {
+		p.Currency = "USD"
+	}
+	return p, nil
+}
+
+// Match is a listing scored against a profile. Excluded listings asked for
+// an excluded technology or couldn't be worked remotely when that is
+// required.
+type Match struct {
+	Listing  Listing     `json:"listing"`
+	Score    float64     `json:"score"`
+	Excluded bool        `json:"excluded"`
+	Criteria []Criterion `json:"criteria"`
+}
+
+// Criterion is how a listing fared on one part of a profile.
+type Criterion struct {
+	Name    string  `json:"name"`
+	Matched bool    `json:"matched"`
+	Points  float64 `json:"points"`
+	Max     float64 `json:"max"`
+	Detail  string  `json:"detail"`
+}
+
+// Score rates how well l fits the profile.
+func (p Profile) Score(l Listing) Match {
+	return p.score(l, p.termPatterns())
+}
+
+// score rates l with the profile's term patterns compiled up front, so
+// ranking many listings doesn't compile them for each.
+func (p Profile) score(l Listing, patterns map[string]*regexp.Regexp) Match {
+	m := Match{Listing: l}
+	techs := splitTechnologies(l.Technologies)
+	text := l.Title + "\n" + l.Technologies + "\n" + l.Description
+
+	// exclusions go by what the listing asks for, not what its description
+	// mentions in passing: "migrating off C++" isn't a C++ job
+	if excluded := mentionedOf(p.Exclude, techs, l.Technologies, patterns); len(excluded) > 0 {
+		m.Excluded = true
+		m.Criteria = append(m.Criteria, Criterion{Name: "exclude", Detail: "asks for " + strings.Join(excluded, ", ")})
+	}
+
+	if len(p.Skills) > 0 {
+		c := Criterion{Name: "skills", Max: skillPoints}
+		var total, matched float64
+		var names []string
+		for _, skill := range p.Skills {
+			total += skill.Weight
+			if len(mentionedOf(append([]string{skill.Name}, skill.Aliases...), techs, text, patterns)) > 0 {
+				matched += skill.Weight
+				names = append(names, skill.Name)
+			}
+		}
+		c.Points = skillPoints * matched / total
+		c.Matched = len(names) > 0
+		if c.Matched {
+			c.Detail = fmt.Sprintf("%s (%d of %d skills)", strings.Join(names, ", "), len(names), len(p.Skills))
+		} else {
+			c.Detail = "none of your skills"
+		}
+		m.Criteria = append(m.Criteria, c)
+	}
+
+	if p.MinPay > 0 {
+		c := Criterion{Name: "pay", Max: payPoints}
+		pay := l.Compensation
+		low, high := pay.Annualized()
+		switch {
+		case pay.IsZero():
+			// not saying isn't a no
+			c.Points = payPoints / 2
+			c.Detail = "pay not stated"
+		case pay.Currency != p.Currency:
+			c.Points = payPoints / 2
+			c.Detail = fmt.Sprintf("pay in %s, not compared", pay.Currency)
+		case math.Max(low, high) >= p.MinPay:
+			c.Matched = true
+			c.Points = payPoints
+			c.Detail = fmt.Sprintf("%s meets %s", l.Pay, formatAmount(p.MinPay))
+		default:
+			c.Detail = fmt.Sprintf("%s is below %s", l.Pay, formatAmount(p.MinPay))
+		}
+		m.Criteria = append(m.Criteria, c)
+	}
+
+	remote := l.WorkLocation.Allows(PolicyRemote)
+	if len(p.Locations) > 0 {
+		c := Criterion{Name: "location", Max: locationPoints}
+		for _, place := range p.Locations {
+			if l.WorkLocation.Mentions(place) {
+				c.Matched = true
+				c.Detail = "in " + place
+				break
+			}
+		}
+		// a remote listing open to anywhere works from any of them
+		if !c.Matched && remote && len(l.WorkLocation.Regions) == 0 {
+			c.Matched = true
+			c.Detail = "remote from anywhere"
+		}
+		if c.Matched {
+			c.Points = locationPoints
+		} else if l.Location == "" {
+			c.Detail = "location not stated"
+		} else {
+			c.Detail = "based in " + l.Location
+		}
+		m.Criteria = append(m.Criteria, c)
+	}
+
+	if p.Remote == RemoteRequired || p.Remote == RemotePreferred {
+		c := Criterion{Name: "remote", Max: remotePoints, Matched: remote}
+		switch {
+		case remote:
+			c.Points = remotePoints
+			c.Detail = "remote"
+		case l.WorkLocation.Allows(PolicyHybrid):
+			c.Points = remotePoints / 2
+			c.Detail = "hybrid"
+		case len(l.WorkLocation.Policies) == 0:
+			c.Detail = "remote policy not stated"
+		default:
+			c.Detail = "onsite only"
+		}
+		// a listing that doesn't say may still be remote
+		if p.Remote == RemoteRequired && !remote && len(l.WorkLocation.Policies) > 0 {
+			m.Excluded = true
+		}
+		m.Criteria = append(m.Criteria, c)
+	}
+
+	var points, total float64
+	for _, c := range m.Criteria {
+		points += c.Points
+		total += c.Max
+	}
+	if total > 0 {
+		m.Score = math.Round(1000*points/total) / 10
+	}
+	return m
+}
+
+// Rank scores listings against the profile, best first, with excluded
+// listings last.
+func (p Profile) Rank(listings []Listing) []Match {
+	patterns := p.termPatterns()
+	matches := make([]Match, len(listings))
+	for i, l := range listings {
+		matches[i] = p.score(l, patterns)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Excluded != matches[j].Excluded {
+			return !matches[i].Excluded
+		}
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// Explain renders the match with what did and didn't fit.
+func (m Match) Explain() string {
+	var b strings.Builder
+	l := m.Listing
+	fmt.Fprintf(&b, "🎯 %5.1f  %s — %s", m.Score, l.Company, l.Title)
+	if l.Location != "" {
+		fmt.Fprintf(&b, " (%s)", l.Location)
+	}
+	if m.Excluded {
+		b.WriteString(" 🚫 excluded")
+	}
+	b.WriteString("\n")
+	for _, c := range m.Criteria {
+		mark := "❌"
+		switch {
+		case c.Matched:
+			mark = "✅"
+		case c.Points > 0:
+			mark = "➖"
+		}
+		fmt.Fprintf(&b, "     %s %-8s %s\n", mark, c.Name, c.Detail)
+	}
+	if l.CommentID != "" {
+		fmt.Fprintf(&b, "     🔗 https://news.ycombinator.com/item?id=%s\n", l.CommentID)
+	}
+	return b.String()
+}
+
+// termPatterns compiles a techWordPattern for every skill, alias and
+// excluded technology long enough to search text for.
+func (p Profile) termPatterns() map[string]*regexp.Regexp {
+	patterns := make(map[string]*regexp.Regexp)
+	names := slices.Clone(p.Exclude)
+	for _, skill := range p.Skills {
+		names = append(append(names, skill.Name), skill.Aliases...)
+	}
+	for _, name := range names {
+		if _, ok := patterns[name]; !ok && len([]rune(name)) > 2 {
+			patterns[name] = techWordPattern(name)
+		}
+	}
+	return patterns
+}
+
+// mentionedOf returns the names a listing asks for, out of names. A name
+// counts when it is one of the listing's technologies, or, for names longer
+// than two characters, when its pattern matches text. Short names like "Go"
+// or "C" are too common in prose to search for.
+func mentionedOf(names, techs []string, text string, patterns map[string]*regexp.Regexp) []string {
+	var found []string
+	for _, name := range names {
+		if slices.ContainsFunc(techs, func(t string) bool { return strings.EqualFold(t, name) }) {
+			found = append(found, name)
+			continue
+		}
+		if pattern, ok := patterns[name]; ok && pattern.MatchString(text) {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+// techWordPattern matches name on its own, so "C++" doesn't match inside
+// "C++11" and "Java" doesn't match "JavaScript".
+func techWordPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}+#])` + regexp.QuoteMeta(name) + `(?:$|[^\p{L}\p{N}+#])`)
+}
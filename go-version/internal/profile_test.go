@@ -0,0 +1,122 @@
+package internal_linkedin_scraper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	profile, err := LoadProfile(write("profile.yaml", `
+skills:
+  - name: Go
+    weight: 3
+    aliases: [Golang]
+  - name: Postgres
+minPay: 150000
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profile.Skills) != 2 || profile.Skills[0].Weight != 3 || profile.Skills[1].Weight != 1 {
+		t.Errorf("skills %+v, want Go at 3 and Postgres at the default weight of 1", profile.Skills)
+	}
+	if profile.Remote != RemoteAny || profile.Currency != "USD" || profile.MinPay != 150000 {
+		t.Errorf("profile %+v: want the remote preference and currency filled in", profile)
+	}
+
+	for name, content := range map[string]string{
+		"remote.json":   `{"remote": "sometimes"}`,
+		"unnamed.json":  `{"skills": [{"weight": 2}]}`,
+		"negative.yaml": "skills:\n  - name: Go\n    weight: -1\n",
+	} {
+		if _, err := LoadProfile(write(name, content)); err == nil {
+			t.Errorf("loaded the invalid profile %s", name)
+		}
+	}
+}
+
+func TestProfileScore(t *testing.T) {
+	profile := Profile{
+		Skills: []Skill{
+			{Name: "Go", Weight: 3, Aliases: []string{"Golang"}},
+			{Name: "React", Weight: 1},
+		},
+		MinPay:    150000,
+		Currency:  "USD",
+		Locations: []string{"NYC"},
+		Remote:    RemotePreferred,
+		Exclude:   []string{"C", "C#", "C++"},
+	}
+	listings := []Listing{
+		enriched(Listing{Company: "Globex", Technologies: "React", Location: "Berlin, onsite", Pay: "$90k", Description: "Frontend work."}),
+		enriched(Listing{Company: "Acme", Technologies: "Go, React", Location: "Remote", Pay: "$160k-$200k", Description: "We write Go services."}),
+		enriched(Listing{Company: "Initech", Technologies: "Python", Location: "NYC, hybrid", Description: "We use Golang for our printers."}),
+		enriched(Listing{Company: "Umbrella", Technologies: "Go, C++", Location: "Remote", Pay: "$200k", Description: "Firmware in C++ and Go."}),
+		enriched(Listing{Company: "Hooli", Technologies: "C, Go", Location: "NYC", Pay: "$250k"}),
+	}
+
+	matches := profile.Rank(listings)
+	var order []string
+	for _, m := range matches {
+		order = append(order, m.Listing.Company)
+	}
+	if got := strings.Join(order, ","); got != "Acme,Initech,Globex,Umbrella,Hooli" {
+		t.Errorf("ranked %s, want Acme,Initech,Globex then the excluded Umbrella and Hooli", got)
+	}
+
+	acme := matches[0]
+	if acme.Score != 100 || acme.Excluded {
+		t.Errorf("Acme scored %.1f (excluded %v), want a full match", acme.Score, acme.Excluded)
+	}
+	// Golang in the description counts for Go, pay not stated for half
+	initech := matches[1]
+	want := map[string]string{
+		"skills":   "Go (1 of 2 skills)",
+		"pay":      "pay not stated",
+		"location": "in NYC",
+		"remote":   "hybrid",
+	}
+	for _, c := range initech.Criteria {
+		if c.Detail != want[c.Name] {
+			t.Errorf("Initech %s: %q, want %q", c.Name, c.Detail, want[c.Name])
+		}
+	}
+	if initech.Score != 70 {
+		t.Errorf("Initech scored %.1f, want 70", initech.Score)
+	}
+
+	for _, m := range matches[3:] {
+		if !m.Excluded || m.Criteria[0].Name != "exclude" {
+			t.Errorf("%s not excluded: %+v", m.Listing.Company, m.Criteria)
+		}
+	}
+	if explained := matches[3].Explain(); !strings.Contains(explained, "asks for C++") {
+		t.Errorf("explanation doesn't say why Umbrella is excluded:\n%s", explained)
+	}
+
+	// a technology the description only mentions isn't asked for
+	migrating := profile.Score(enriched(Listing{Company: "Soylent", Technologies: "Go", Location: "Remote", Description: "We're migrating off C++ to Go."}))
+	if migrating.Excluded {
+		t.Errorf("Soylent excluded for mentioning C++: %+v", migrating.Criteria)
+	}
+
+	// remote wording about the culture doesn't make an onsite job remote
+	onsite := profile.Score(enriched(Listing{Company: "Vandelay", Technologies: "Go", Location: "Onsite in NYC, remote-friendly culture"}))
+	for _, c := range onsite.Criteria {
+		if c.Name == "remote" && (c.Matched || c.Detail != "onsite only") {
+			t.Errorf("Vandelay remote: %+v, want onsite only", c)
+		}
+	}
+}
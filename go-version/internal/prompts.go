@@ -0,0 +1,152 @@
+package internal_linkedin_scraper
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Names of the prompt templates, each stored as prompts/<name>.tmpl.
+const (
+	PromptSerialize        = "serialize"
+	PromptWhoIsHiring      = "who_is_hiring"
+	PromptHiringFreelance  = "hiring_freelance"
+	PromptSeekingFreelance = "seeking_freelance"
+	PromptJobSeeker        = "job_seeker"
+)
+
+//go:embed prompts/*.tmpl
+var defaultPrompts embed.FS
+
+// PromptVars are the variables every template can use.
+type PromptVars struct {
+	// Technologies the legacy prompts look for. Which listings are worth
+	// applying to is up to the Profile, not the prompts.
+	Technologies []string `json:"technologies"`
+
+	// Schema is the JSON schema to spell out in prompts for models that
+	// can't be given one directly. It is set per request.
+	Schema string `json:"-"`
+}
+
+// Prompt is a rendered template. Version names the template and a hash of
+// the text, so any change to the template, its variables or the schema
+// gives a new version.
+type Prompt struct {
+	Text    string
+	Version string
+}
+
+var promptFuncs = template.FuncMap{
+	"allOf": func(items []string) string { return joinList(items, "and") },
+	"anyOf": func(items []string) string { return joinList(items, "or") },
+}
+
+var (
+	promptsMu sync.RWMutex
+	prompts   = mustParseDefaultPrompts()
+
+	promptVars = PromptVars{
+		Technologies: []string{"React", "Vue", "Golang", "Go", "AWS"},
+	}
+)
+
+func mustParseDefaultPrompts() map[string]*template.Template {
+	files, err := defaultPrompts.ReadDir("prompts")
+	if err != nil {
+		log.Fatal(err)
+	}
+	parsed := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		source, err := defaultPrompts.ReadFile("prompts/" + file.Name())
+		if err != nil {
+			log.Fatal(err)
+		}
+		name := strings.TrimSuffix(file.Name(), ".tmpl")
+		parsed[name] = template.Must(template.New(name).Funcs(promptFuncs).Parse(string(source)))
+	}
+	return parsed
+}
+
+// LoadPrompts overrides the embedded templates with any <name>.tmpl in dir,
+// and the default variables with dir/vars.json if it exists. A missing
+// directory leaves the defaults in place.
+func LoadPrompts(dir string) error {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	promptsMu.Lock()
+	defer promptsMu.Unlock()
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name())
+		switch {
+		case strings.HasSuffix(file.Name(), ".tmpl"):
+			source, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSuffix(file.Name(), ".tmpl")
+			tmpl, err := template.New(name).Funcs(promptFuncs).Parse(string(source))
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			prompts[name] = tmpl
+		case file.Name() == "vars.json":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, &promptVars); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// renderPrompt fills in the named template. schema is passed to the
+// template as .Schema and may be empty.
+func renderPrompt(name, schema string) Prompt {
+	promptsMu.RLock()
+	tmpl, ok := prompts[name]
+	vars := promptVars
+	promptsMu.RUnlock()
+	if !ok {
+		log.Fatalf("unknown prompt template %q", name)
+	}
+
+	vars.Schema = schema
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		log.Fatalf("failed to render prompt %q: %v", name, err)
+	}
+	text := strings.TrimSpace(b.String())
+	sum := sha256.Sum256([]byte(text))
+	return Prompt{Text: text, Version: name + "@" + hex.EncodeToString(sum[:])[:8]}
+}
+
+// joinList joins items as in "a, b, and c".
+func joinList(items []string, conjunction string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " " + conjunction + " " + items[1]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + ", " + conjunction + " " + items[len(items)-1]
+}
@@ -0,0 +1,40 @@
+package internal_linkedin_scraper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPromptsOverridesTemplatesAndVars(t *testing.T) {
+	prevPrompts, prevVars := prompts, promptVars
+	prompts = mustParseDefaultPrompts()
+	t.Cleanup(func() { prompts, promptVars = prevPrompts, prevVars })
+
+	defaults := renderPrompt(PromptSerialize, "")
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, PromptSerialize+".tmpl"), []byte("Extract jobs using {{anyOf .Technologies}}."), 0644)
+	os.WriteFile(filepath.Join(dir, "vars.json"), []byte(`{"technologies": ["Rust", "Elixir", "Zig"]}`), 0644)
+	if err := LoadPrompts(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	got := renderPrompt(PromptSerialize, "")
+	if got.Text != "Extract jobs using Rust, Elixir, or Zig." {
+		t.Errorf("overridden prompt = %q", got.Text)
+	}
+	if got.Version == defaults.Version || !strings.HasPrefix(got.Version, PromptSerialize+"@") {
+		t.Errorf("version = %q, want a new %s version", got.Version, PromptSerialize)
+	}
+	if who := renderPrompt(PromptWhoIsHiring, ""); !strings.Contains(who.Text, "Rust, Elixir, and Zig") {
+		t.Error("embedded template didn't pick up the overridden variables")
+	}
+}
+
+func TestLoadPromptsWithoutDirectory(t *testing.T) {
+	if err := LoadPrompts(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("LoadPrompts on a missing directory = %v, want nil", err)
+	}
+}
@@ -0,0 +1,103 @@
+package internal_linkedin_scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// reportFileName is written next to a run's other output.
+const reportFileName = "run-report.json"
+
+// ModelUsage is the token usage and cost of the calls made to one model.
+type ModelUsage struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// RunReport is the cost and outcome of a run. Costs are in US dollars and
+// estimated from the usage the API reported and the configured prices.
+type RunReport struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+
+	Pages    int `json:"pages"`
+	Comments int `json:"comments"`
+	Listings int `json:"listings"`
+
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	Cost             float64 `json:"cost"`
+	CostPerPage      float64 `json:"costPerPage"`
+	CostPerListing   float64 `json:"costPerListing"`
+
+	CacheHits int `json:"cacheHits"`
+	Failures  int `json:"failures"`
+	Fallbacks int `json:"fallbacks"`
+	Degraded  int `json:"degraded"`
+	Skipped   int `json:"skipped"`
+
+	Duplicates int `json:"duplicates"`
+
+	Models map[string]ModelUsage `json:"models"`
+}
+
+// Report totals the counters collected so far.
+func (s *RunStats) Report() RunReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := RunReport{
+		StartedAt:  s.StartedAt,
+		FinishedAt: time.Now(),
+		Pages:      s.Pages,
+		Comments:   s.Comments,
+		Listings:   s.Listings,
+		CacheHits:  s.CacheHits,
+		Failures:   s.Failures,
+		Fallbacks:  s.Fallbacks,
+		Degraded:   s.Degraded,
+		Skipped:    s.Skipped,
+		Duplicates: s.Duplicates,
+		Models:     make(map[string]ModelUsage, len(s.Usage)),
+	}
+	for model, u := range s.Usage {
+		r.Models[model] = *u
+		r.PromptTokens += u.PromptTokens
+		r.CompletionTokens += u.CompletionTokens
+		r.Cost += u.Cost
+	}
+	if r.Pages > 0 {
+		r.CostPerPage = r.Cost / float64(r.Pages)
+	}
+	if r.Listings > 0 {
+		r.CostPerListing = r.Cost / float64(r.Listings)
+	}
+	return r
+}
+
+// LastRunReport returns the report of the most recent Run or
+// ScrapeHackerNews call.
+func LastRunReport() RunReport {
+	return runStats.Report()
+}
+
+// writeReport saves the current run's report as JSON in dir.
+func writeReport(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(runStats.Report(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, reportFileName)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write run report: %w", err)
+	}
+	return path, nil
+}
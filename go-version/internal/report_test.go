@@ -0,0 +1,40 @@
+package internal_linkedin_scraper
+
+import (
+	"testing"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+func TestRunStatsReport(t *testing.T) {
+	s := newRunStats()
+	for range 2 {
+		s.recordPage()
+	}
+	s.recordComment(3)
+	s.recordComment(1)
+	s.recordCacheHit()
+	s.recordUsage(gpt.GPT4oMini, gpt.Usage{PromptTokens: 1000, CompletionTokens: 200}, 0.25)
+	s.recordUsage(gpt.GPT4oMini, gpt.Usage{PromptTokens: 500, CompletionTokens: 100}, 0.25)
+	s.recordUsage(gpt.GPT4o, gpt.Usage{PromptTokens: 100, CompletionTokens: 50}, 1.5)
+
+	r := s.Report()
+	if r.Pages != 2 || r.Comments != 2 || r.Listings != 4 || r.CacheHits != 1 {
+		t.Errorf("%d pages, %d comments, %d listings, %d cache hits; want 2, 2, 4, 1", r.Pages, r.Comments, r.Listings, r.CacheHits)
+	}
+	if r.PromptTokens != 1600 || r.CompletionTokens != 350 || r.Cost != 2 {
+		t.Errorf("totals: %d prompt, %d completion, $%v; want 1600, 350, $2", r.PromptTokens, r.CompletionTokens, r.Cost)
+	}
+	if r.CostPerPage != 1 || r.CostPerListing != 0.5 {
+		t.Errorf("$%v per page, $%v per listing; want $1 and $0.5", r.CostPerPage, r.CostPerListing)
+	}
+	mini := r.Models[gpt.GPT4oMini]
+	if len(r.Models) != 2 || mini.Requests != 2 || mini.PromptTokens != 1500 || mini.Cost != 0.5 {
+		t.Errorf("models %+v, want gpt-4o-mini with 2 requests, 1500 prompt tokens and $0.5", r.Models)
+	}
+
+	// an empty run divides by nothing
+	if r := newRunStats().Report(); r.CostPerPage != 0 || r.CostPerListing != 0 {
+		t.Errorf("empty run: $%v per page, $%v per listing; want 0", r.CostPerPage, r.CostPerListing)
+	}
+}
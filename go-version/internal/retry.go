@@ -0,0 +1,146 @@
+package internal_linkedin_scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+// retryPolicy decides whether a failed API call is worth repeating and how
+// long to wait first. Server hints (Retry-After, x-ratelimit-reset-*) take
+// precedence over exponential backoff; both are jittered and clamped to
+// [BaseDelay, MaxDelay].
+type retryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  retryBaseDelay * time.Millisecond,
+	MaxDelay:   maxRetryDelay * time.Millisecond,
+}
+
+// retryable reports whether err is a rate limit or transient server error.
+// Running out of quota also comes back as a 429 but won't fix itself.
+func (p retryPolicy) retryable(err error) bool {
+	var apiErr *gpt.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Code == "insufficient_quota" || apiErr.Type == "insufficient_quota" {
+			return false
+		}
+		return retryableStatus(apiErr.HTTPStatusCode)
+	}
+	var reqErr *gpt.RequestError
+	if errors.As(err, &reqErr) {
+		return retryableStatus(reqErr.HTTPStatusCode)
+	}
+	return false
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// delay returns how long to wait before retry number attempt (starting at 1)
+// given the headers of the failed response.
+func (p retryPolicy) delay(attempt int, header http.Header) time.Duration {
+	wait := retryAfter(header)
+	if wait <= 0 {
+		wait = p.BaseDelay << (attempt - 1)
+		if wait <= 0 || wait > p.MaxDelay {
+			wait = p.MaxDelay
+		}
+	}
+	// add up to 20% jitter so concurrent workers don't retry in lockstep
+	if jitter := int64(wait) / 5; jitter > 0 {
+		wait += time.Duration(rand.Int63n(jitter))
+	}
+	if wait < p.BaseDelay {
+		wait = p.BaseDelay
+	}
+	if wait > p.MaxDelay {
+		wait = p.MaxDelay
+	}
+	return wait
+}
+
+// retryAfter reads the server's hint for when to retry: Retry-After (seconds
+// or an HTTP date), retry-after-ms, or the later of the x-ratelimit-reset-*
+// durations.
+func retryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+	if ms, err := strconv.ParseFloat(header.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second))
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return time.Until(at)
+		}
+	}
+	var wait time.Duration
+	for _, name := range []string{"x-ratelimit-reset-requests", "x-ratelimit-reset-tokens"} {
+		if d, err := time.ParseDuration(header.Get(name)); err == nil && d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// do calls fn until it succeeds, fails with an error that isn't retryable,
+// or MaxRetries is exhausted. fn must use the context it is given so the
+// response headers can be captured.
+func (p retryPolicy) do(ctx context.Context, fn func(context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		callCtx, sink := withHeaderSink(ctx)
+		err := fn(callCtx)
+		if err == nil || !p.retryable(err) {
+			return err
+		}
+		if attempt >= p.MaxRetries {
+			return fmt.Errorf("max retries exceeded for rate limiting: %w", err)
+		}
+
+		wait := p.delay(attempt+1, sink.get())
+		fmt.Printf("🚨 Rate limit hit! Waiting %v before retry (attempt %d/%d)...\n", wait, attempt+1, p.MaxRetries)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// createChatCompletion holds a limiter slot only while a request is in
+// flight, not while backing off between attempts.
+func (p retryPolicy) createChatCompletion(ctx context.Context, client *gpt.Client, limiter *adaptiveLimiter, req gpt.ChatCompletionRequest) (gpt.ChatCompletionResponse, error) {
+	var resp gpt.ChatCompletionResponse
+	err := p.do(ctx, func(ctx context.Context) error {
+		if err := limiter.acquire(ctx); err != nil {
+			return err
+		}
+		defer limiter.release()
+
+		var err error
+		resp, err = client.CreateChatCompletion(ctx, req)
+		return err
+	})
+	return resp, err
+}
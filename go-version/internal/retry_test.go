@@ -0,0 +1,137 @@
+package internal_linkedin_scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+const completionBody = `{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`
+
+var testRetryPolicy = retryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  time.Millisecond,
+	MaxDelay:   20 * time.Millisecond,
+}
+
+// fakeOpenAI serves the responses in order, repeating the last one, and
+// counts how many requests it received.
+func fakeOpenAI(t *testing.T, responses ...func(http.ResponseWriter)) (*gpt.Client, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		w.Header().Set("Content-Type", "application/json")
+		responses[min(n, len(responses))-1](w)
+	}))
+	t.Cleanup(server.Close)
+	return newOpenAIClient("test-key", server.URL+"/v1", nil), &calls
+}
+
+func rateLimited(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "0.005")
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`))
+}
+
+func ok(w http.ResponseWriter) {
+	w.Write([]byte(completionBody))
+}
+
+func chatRequest() gpt.ChatCompletionRequest {
+	return gpt.ChatCompletionRequest{
+		Model:    gpt.GPT4oMini,
+		Messages: []gpt.ChatCompletionMessage{{Role: gpt.ChatMessageRoleUser, Content: "hi"}},
+	}
+}
+
+func TestRetryPolicyRecoversFromRateLimit(t *testing.T) {
+	client, calls := fakeOpenAI(t, rateLimited, rateLimited, ok)
+
+	resp, err := testRetryPolicy.createChatCompletion(context.Background(), client, nil, chatRequest())
+	if err != nil {
+		t.Fatalf("createChatCompletion: %v", err)
+	}
+	if got := resp.Choices[0].Message.Content; got != "ok" {
+		t.Errorf("content = %q, want %q", got, "ok")
+	}
+	if *calls != 3 {
+		t.Errorf("calls = %d, want 3", *calls)
+	}
+}
+
+func TestRetryPolicyGivesUpAfterMaxRetries(t *testing.T) {
+	client, calls := fakeOpenAI(t, rateLimited)
+
+	_, err := testRetryPolicy.createChatCompletion(context.Background(), client, nil, chatRequest())
+	var apiErr *gpt.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want a wrapped 429 APIError", err)
+	}
+	if want := int32(testRetryPolicy.MaxRetries + 1); *calls != want {
+		t.Errorf("calls = %d, want %d", *calls, want)
+	}
+}
+
+func TestRetryPolicyDoesNotRetryPermanentErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"bad request", http.StatusBadRequest, `{"error":{"message":"bad schema","type":"invalid_request_error"}}`},
+		{"unauthorized", http.StatusUnauthorized, `{"error":{"message":"bad key","type":"invalid_request_error"}}`},
+		{"out of quota", http.StatusTooManyRequests, `{"error":{"message":"quota","type":"insufficient_quota","code":"insufficient_quota"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, calls := fakeOpenAI(t, func(w http.ResponseWriter) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			if _, err := testRetryPolicy.createChatCompletion(context.Background(), client, nil, chatRequest()); err == nil {
+				t.Fatal("expected an error")
+			}
+			if *calls != 1 {
+				t.Errorf("calls = %d, want 1", *calls)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := retryPolicy{MaxRetries: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 10 * time.Second}
+	tests := []struct {
+		name     string
+		attempt  int
+		header   http.Header
+		min, max time.Duration
+	}{
+		{"backoff without hints", 1, nil, 100 * time.Millisecond, 120 * time.Millisecond},
+		{"backoff doubles", 3, nil, 400 * time.Millisecond, 480 * time.Millisecond},
+		{"backoff is capped", 10, nil, 10 * time.Second, 10 * time.Second},
+		{"retry-after seconds", 1, http.Header{"Retry-After": {"2"}}, 2 * time.Second, 2400 * time.Millisecond},
+		{"retry-after-ms", 1, http.Header{"Retry-After-Ms": {"607"}}, 607 * time.Millisecond, 729 * time.Millisecond},
+		{"later reset header wins", 1, http.Header{
+			"X-Ratelimit-Reset-Requests": {"120ms"},
+			"X-Ratelimit-Reset-Tokens":   {"1.5s"},
+		}, 1500 * time.Millisecond, 1800 * time.Millisecond},
+		{"hint below base delay", 1, http.Header{"Retry-After-Ms": {"1"}}, 100 * time.Millisecond, 100 * time.Millisecond},
+		{"hint above max delay", 1, http.Header{"X-Ratelimit-Reset-Tokens": {"6m0s"}}, 10 * time.Second, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.delay(tt.attempt, tt.header)
+			if got < tt.min || got > tt.max {
+				t.Errorf("delay = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package internal_linkedin_scraper
+
+import (
+	"net/http"
+	"testing"
+)
+
+const acmeComment = "Acme | Senior Go Engineer | Remote | $150k\nWe build rockets. Email jobs@acme.com"
+
+// useFakeOpenAI points the package at a fake server serving responses and
+// resets the run state, undoing it when the test ends.
+func useFakeOpenAI(t *testing.T, responses ...func(http.ResponseWriter)) *int32 {
+	t.Helper()
+	client, calls := fakeOpenAI(t, responses...)
+	prevClient, prevStats, prevBudget, prevCache := oaiclient, runStats, budget, CacheEnabled
+	t.Cleanup(func() {
+		oaiclient, runStats, budget, CacheEnabled = prevClient, prevStats, prevBudget, prevCache
+	})
+	oaiclient = client
+	runStats = newRunStats()
+	budget = &runBudget{}
+	CacheEnabled = false
+	return calls
+}
+
+func respond(body string) func(http.ResponseWriter) {
+	return func(w http.ResponseWriter) { w.Write([]byte(body)) }
+}
+
+func fail(status int, body string) func(http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+var invalidJSON = respond(completion(`{"company":`))
+
+func TestSerializeListingRepairsInvalidResponse(t *testing.T) {
+	calls := useFakeOpenAI(t, invalidJSON, respond(completion(acmePost)))
+
+	listings, err := serializeListing(acmeComment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 1 || listings[0].Company != "Acme" || listings[0].Degraded {
+		t.Errorf("listings %+v, want the repaired Acme listing", listings)
+	}
+	if *calls != 2 || runStats.Repairs != 1 || runStats.Fallbacks != 0 {
+		t.Errorf("%d calls, %d repairs, %d fallbacks; want 2, 1, 0", *calls, runStats.Repairs, runStats.Fallbacks)
+	}
+}
+
+func TestSerializeListingFallsBackToJSONMode(t *testing.T) {
+	responses := make([]func(http.ResponseWriter), maxRepairAttempts+1)
+	for i := range responses {
+		responses[i] = invalidJSON
+	}
+	calls := useFakeOpenAI(t, append(responses, respond(completion(acmePost)))...)
+
+	listings, err := serializeListing(acmeComment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 1 || listings[0].Degraded {
+		t.Errorf("listings %+v, want the JSON mode listing", listings)
+	}
+	if want := int32(maxRepairAttempts + 2); *calls != want || runStats.Fallbacks != 1 || runStats.Degraded != 0 {
+		t.Errorf("%d calls, %d fallbacks, %d degraded; want %d, 1, 0", *calls, runStats.Fallbacks, runStats.Degraded, want)
+	}
+}
+
+func TestSerializeListingFallsBackToJSONModeOnUnsupportedFormat(t *testing.T) {
+	unsupported := fail(http.StatusBadRequest, `{"error":{"message":"Invalid schema","type":"invalid_request_error","param":"response_format","code":null}}`)
+	calls := useFakeOpenAI(t, unsupported, respond(completion(acmePost)))
+
+	listings, err := serializeListing(acmeComment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 1 || *calls != 2 || runStats.Fallbacks != 1 {
+		t.Errorf("%d listings after %d calls and %d fallbacks, want 1 after 2 and 1", len(listings), *calls, runStats.Fallbacks)
+	}
+}
+
+func TestSerializeListingUsesRuleBasedFallback(t *testing.T) {
+	useFakeOpenAI(t, invalidJSON)
+
+	listings, err := serializeListing(acmeComment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 1 || !listings[0].Degraded || listings[0].Company != "Acme" || listings[0].Title != "Senior Go Engineer" {
+		t.Errorf("listings %+v, want a degraded Acme listing from the header", listings)
+	}
+	if runStats.Degraded != 1 {
+		t.Errorf("degraded = %d, want 1", runStats.Degraded)
+	}
+
+	// a comment the fallback can't read isn't counted as degraded
+	runStats = newRunStats()
+	listings, err = serializeListing("We're hiring engineers, get in touch!")
+	if err != nil || listings != nil || runStats.Degraded != 0 {
+		t.Errorf("got %v, %v and %d degraded; want no listings, no error and none degraded", listings, err, runStats.Degraded)
+	}
+}
+
+func TestSerializeListingReturnsAPIErrors(t *testing.T) {
+	calls := useFakeOpenAI(t, fail(http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`))
+
+	listings, err := serializeListing(acmeComment)
+	if err == nil || listings != nil {
+		t.Fatalf("got %v, %v; want the API error and no listings", listings, err)
+	}
+	if *calls != 1 || runStats.Fallbacks != 0 || runStats.Degraded != 0 {
+		t.Errorf("%d calls, %d fallbacks, %d degraded; want 1 call and no fallback", *calls, runStats.Fallbacks, runStats.Degraded)
+	}
+}
@@ -0,0 +1,184 @@
+package internal_linkedin_scraper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gpt "github.com/sashabaranov/go-openai"
+)
+
+// RunStats collects counters across a single Run so they can be summarised
+// once it finishes. It is safe for concurrent use.
+type RunStats struct {
+	mu sync.Mutex
+
+	StartedAt time.Time `json:"startedAt"`
+
+	Pages    int `json:"pages"`
+	Comments int `json:"comments"`
+	Listings int `json:"listings"`
+
+	// Repairs counts responses sent back to the model after failing schema
+	// validation, Fallbacks comments that needed JSON mode, and Degraded
+	// comments that could only be handled by the rule-based extractor.
+	Repairs   int `json:"repairs"`
+	Fallbacks int `json:"fallbacks"`
+	Degraded  int `json:"degraded"`
+
+	// Skipped counts comments left unprocessed once the run budget ran out.
+	Skipped int `json:"skipped"`
+
+	// Duplicates counts postings merged into another before extraction.
+	Duplicates int `json:"duplicates"`
+
+	// Failures counts API calls that failed for good, and CacheHits calls
+	// answered without reaching the API.
+	Failures  int `json:"failures"`
+	CacheHits int `json:"cacheHits"`
+
+	// Usage is the token usage and cost of the run's API calls, by model.
+	Usage map[string]*ModelUsage `json:"usage"`
+
+	// FieldsChecked and FieldsUngrounded count, per Listing field, how many
+	// values were grounding-checked and how many weren't found in the source.
+	FieldsChecked    map[string]int `json:"fieldsChecked"`
+	FieldsUngrounded map[string]int `json:"fieldsUngrounded"`
+}
+
+var runStats = newRunStats()
+
+func newRunStats() *RunStats {
+	return &RunStats{
+		StartedAt:        time.Now(),
+		Usage:            make(map[string]*ModelUsage),
+		FieldsChecked:    make(map[string]int),
+		FieldsUngrounded: make(map[string]int),
+	}
+}
+
+func (s *RunStats) recordPage() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Pages++
+}
+
+func (s *RunStats) recordComment(listings int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Comments++
+	s.Listings += listings
+}
+
+func (s *RunStats) recordRepair() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Repairs++
+}
+
+func (s *RunStats) recordFallback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Fallbacks++
+}
+
+func (s *RunStats) recordDegraded() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Degraded++
+}
+
+func (s *RunStats) recordSkipped() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Skipped++
+}
+
+func (s *RunStats) recordDuplicates(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Duplicates += n
+}
+
+func (s *RunStats) recordFailure() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Failures++
+}
+
+func (s *RunStats) recordCacheHit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CacheHits++
+}
+
+func (s *RunStats) recordUsage(model string, usage gpt.Usage, cost float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.Usage[model]
+	if !ok {
+		u = &ModelUsage{}
+		s.Usage[model] = u
+	}
+	u.Requests++
+	u.PromptTokens += usage.PromptTokens
+	u.CompletionTokens += usage.CompletionTokens
+	u.Cost += cost
+}
+
+func (s *RunStats) recordGrounding(field string, grounded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.FieldsChecked[field]++
+	if !grounded {
+		s.FieldsUngrounded[field]++
+	}
+}
+
+// HallucinationRate returns the share of checked values for field that
+// weren't supported by the source text.
+func (s *RunStats) HallucinationRate(field string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.FieldsChecked[field] == 0 {
+		return 0
+	}
+	return float64(s.FieldsUngrounded[field]) / float64(s.FieldsChecked[field])
+}
+
+// Summary renders the counters as a short human-readable report.
+func (s *RunStats) Summary() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📊 Run summary: %d comments → %d listings\n", s.Comments, s.Listings)
+	fmt.Fprintf(&b, "  🔧 %d schema repairs, %d JSON mode fallbacks, %d degraded\n", s.Repairs, s.Fallbacks, s.Degraded)
+	if s.Skipped > 0 {
+		fmt.Fprintf(&b, "  💸 %d comments skipped after reaching the run budget\n", s.Skipped)
+	}
+	if s.Duplicates > 0 {
+		fmt.Fprintf(&b, "  🔁 %d duplicate postings merged before extraction\n", s.Duplicates)
+	}
+	var promptTokens, completionTokens int
+	var cost float64
+	for _, u := range s.Usage {
+		promptTokens += u.PromptTokens
+		completionTokens += u.CompletionTokens
+		cost += u.Cost
+	}
+	fmt.Fprintf(&b, "  💰 %d tokens in, %d out, $%.4f (%d cache hits, %d failed calls)\n", promptTokens, completionTokens, cost, s.CacheHits, s.Failures)
+
+	fields := make([]string, 0, len(s.FieldsChecked))
+	for field := range s.FieldsChecked {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		checked, ungrounded := s.FieldsChecked[field], s.FieldsUngrounded[field]
+		fmt.Fprintf(&b, "  🔎 %-12s %d/%d ungrounded (%.1f%%)\n", field, ungrounded, checked, float64(ungrounded)/float64(checked)*100)
+	}
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package internal_linkedin_scraper
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Posting statuses. HN shows dead comments, killed by flags or by the
+// moderators, the same way as flagged ones, so both count as flagged.
+const (
+	StatusOpen    = "open"
+	StatusFilled  = "filled"
+	StatusFlagged = "flagged"
+	StatusDeleted = "deleted"
+)
+
+// IncludeClosed makes exports keep filled, flagged and deleted listings.
+var IncludeClosed = false
+
+var (
+	// closedTagPattern matches a posting marked as taken up front:
+	// "[CLOSED] Acme | ...", "FILLED - Acme | ..."
+	closedTagPattern = regexp.MustCompile(`(?i)^\W*(?:\[\s*(?:closed|filled|no longer hiring)\s*\]|(?:closed|filled)\s*(?:[:!\]]|-\s|–|$))`)
+	// editPattern finds the edits posters add to a posting later: "EDIT:",
+	// "Update 2:"
+	editPattern = regexp.MustCompile(`(?i)\b(?:edit|update)d?\s*\d*\s*:`)
+	// filledPattern matches the ways an edit or a poster's reply says the
+	// role is taken. Postings themselves are only read for it in their edits,
+	// since "positions filled quickly" in a pitch isn't a closing notice.
+	filledPattern = regexp.MustCompile(`(?i)^\W*(?:closed|filled)\b|\[\s*(?:closed|filled|no longer hiring)\s*\]|\b(?:position|positions|role|roles|job|jobs|opening|openings|spot|req)\s+(?:(?:has|have|is|are|was|were)\s+(?:been\s+|now\s+)?|been\s+)(?:filled|closed|taken)\b|\bno longer (?:hiring|accepting|available)\b|\b(?:we(?:'ve| have)|i(?:'ve| have))\s+(?:already\s+)?filled\s+(?:the|this|that|both|all)\b`)
+	// negatedPattern spots a match that says the opposite, like "EDIT: not
+	// yet filled", in the match itself or the words just before it
+	negatedPattern = regexp.MustCompile(`(?i)\b(?:not|yet|still|never)\b|n't\b`)
+	// stillOpenPattern spots an update that closes one role but keeps another
+	// open: "Senior role filled, Junior still open"
+	stillOpenPattern = regexp.MustCompile(`(?i)\bstill\s+(?:open|hiring|looking|available|accepting)\b`)
+	markerPattern    = regexp.MustCompile(`\[(flagged|dead|deleted)\]`)
+)
+
+// commentStatus tells from a comment row whether HN flagged or deleted it.
+// Deleted comments keep their header but lose their text, and flagged or
+// dead ones are marked in the header next to the age.
+func commentStatus(row *goquery.Selection, text string) string {
+	markers := markerPattern.FindAllStringSubmatch(row.Find(".comhead").Text()+" "+strings.TrimSpace(text), -1)
+	status := ""
+	for _, m := range markers {
+		switch m[1] {
+		case "deleted":
+			return StatusDeleted
+		case "flagged", "dead":
+			status = StatusFlagged
+		}
+	}
+	if status != "" {
+		return status
+	}
+	if row.Find(".commtext.cdd").Length() > 0 {
+		return StatusFlagged
+	}
+	if row.Find(".commtext").Length() == 0 {
+		return StatusDeleted
+	}
+	if isFilled(text) {
+		return StatusFilled
+	}
+	return StatusOpen
+}
+
+// isFilled reports whether a posting says its role is no longer open,
+// either marked so at the start or in an edit line added later.
+func isFilled(text string) bool {
+	if closedTagPattern.MatchString(text) {
+		return true
+	}
+	for _, m := range editPattern.FindAllStringIndex(text, -1) {
+		edit, _, _ := strings.Cut(text[m[1]:], "\n")
+		if saysFilled(edit) {
+			return true
+		}
+	}
+	return false
+}
+
+// saysFilled reports whether an edit or a poster's reply says the role is
+// no longer open. One that also says something is still open keeps the
+// posting open.
+func saysFilled(text string) bool {
+	if stillOpenPattern.MatchString(text) {
+		return false
+	}
+	for _, m := range filledPattern.FindAllStringIndex(text, -1) {
+		before := text[max(0, m[0]-12):m[0]]
+		if !negatedPattern.MatchString(before + " " + text[m[0]:m[1]]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Status is the posting's own status, or filled when the poster said so in
+// a reply.
+func (p Posting) Status() string {
+	if status := p.Comment.Status; status != "" && status != StatusOpen {
+		return status
+	}
+	for _, update := range p.Updates() {
+		if saysFilled(update.Text) {
+			return StatusFilled
+		}
+	}
+	return StatusOpen
+}
+
+// IsClosed reports whether the listing is no longer worth applying to.
+func (l Listing) IsClosed() bool {
+	return l.Status != "" && l.Status != StatusOpen
+}
+
+// exportable drops closed listings unless IncludeClosed is set.
+func exportable(listings []Listing) []Listing {
+	if IncludeClosed {
+		return listings
+	}
+	open := make([]Listing, 0, len(listings))
+	for _, l := range listings {
+		if !l.IsClosed() {
+			open = append(open, l)
+		}
+	}
+	return open
+}
@@ -0,0 +1,135 @@
+package internal_linkedin_scraper
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// commentRow renders a comment row the way HN does, trimmed to the parts the
+// parser reads.
+func commentRow(id string, indent int, author, marker, body string) string {
+	return fmt.Sprintf(`<tr class="athing comtr" id="%s"><td><table><tr><td class="ind" indent="%d"></td><td class="default">
+<div><span class="comhead"><a href="user?id=%s" class="hnuser">%s</a> <span class="age" title="2025-06-02T15:01:12 1748876472"><a href="item?id=%s">3 hours ago</a></span> %s</span></div>
+<br><div class="comment">%s</div></td></tr></table></td></tr>`, id, indent, author, author, id, marker, body)
+}
+
+func TestCommentStatus(t *testing.T) {
+	page := `<html><body><table class="comment-tree">` +
+		commentRow("1", 0, "acme", "", `<div class="commtext c00">Acme | Go Engineer | Remote</div>`) +
+		commentRow("2", 1, "someone", "", `<div class="commtext c00">Is this role still open?</div>`) +
+		commentRow("3", 2, "acme", "", `<div class="commtext c00">Sorry, this role has been filled.</div>`) +
+		commentRow("4", 0, "globex", "", `<div class="commtext c00">[CLOSED] Globex | Frontend Engineer | Berlin</div>`) +
+		commentRow("5", 0, "spammer", "[flagged]", `<div class="commtext c00">Make $$$ from home</div>`) +
+		commentRow("6", 0, "ghost", "[dead]", `<div class="commtext cdd">Initech | Printer Firmware</div>`) +
+		commentRow("7", 0, "", "[deleted]", ``) +
+		commentRow("8", 0, "closedloop", "", `<div class="commtext c00">Closed-Loop Robotics | Controls Engineer | Boston</div>`) +
+		`</table></body></html>`
+
+	comments, err := parseComments(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"1": StatusOpen, "2": StatusOpen, "3": StatusOpen, "4": StatusFilled,
+		"5": StatusFlagged, "6": StatusFlagged, "7": StatusDeleted, "8": StatusOpen,
+	}
+	for _, c := range comments {
+		if c.Status != want[c.ID] {
+			t.Errorf("comment %s status = %q, want %q", c.ID, c.Status, want[c.ID])
+		}
+	}
+
+	postings := groupPostings(comments)
+	if len(postings) != 6 {
+		t.Fatalf("got %d postings, want 6", len(postings))
+	}
+	// the poster's reply closes the posting above it
+	if got := postings[0].Status(); got != StatusFilled {
+		t.Errorf("Acme posting status = %q, want %q from the poster's reply", got, StatusFilled)
+	}
+}
+
+func TestWriteCSVExcludesClosedListings(t *testing.T) {
+	listings := []Listing{
+		{Company: "Acme", Status: StatusOpen},
+		{Company: "Globex", Status: StatusFilled},
+		{Company: "Initech", Status: StatusFlagged},
+		{Company: "Hooli"},
+	}
+	companies := func() []string {
+		var b bytes.Buffer
+		if err := WriteCSV(&b, listings); err != nil {
+			t.Fatal(err)
+		}
+		rows, _ := csv.NewReader(&b).ReadAll()
+		var names []string
+		for _, row := range rows[1:] {
+			names = append(names, row[1])
+		}
+		return names
+	}
+
+	if got := strings.Join(companies(), ","); got != "Acme,Hooli" {
+		t.Errorf("exported %s, want only the open listings", got)
+	}
+
+	prev := IncludeClosed
+	IncludeClosed = true
+	t.Cleanup(func() { IncludeClosed = prev })
+	if got := len(companies()); got != len(listings) {
+		t.Errorf("exported %d listings with IncludeClosed, want %d", got, len(listings))
+	}
+}
+
+func TestIsFilled(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"[CLOSED] Acme | Go Engineer | Remote", true},
+		{"FILLED - Acme | Go Engineer | Remote", true},
+		{"Acme | Go Engineer | Remote\nEDIT: filled, thanks everyone", true},
+		{"Acme | Go Engineer | Remote\nUpdate: this role is now filled", true},
+		{"Acme | Go Engineer | Remote", false},
+		{"Closed-Loop Robotics | Controls Engineer | Boston", false},
+		{"Update: role not yet filled, still hiring!", false},
+		{"EDIT: not filled, still looking", false},
+		{"Edit: Senior role filled, Junior still open", false},
+		{"Update: the role hasn't been filled", false},
+		// hiring prose outside an edit stays open
+		{"We have filled our seed round and are now hiring", false},
+		{"Acme | Go Engineer | Remote\nPositions filled quickly, apply soon!", false},
+		{"We have hired for 3 roles this year; we need 2 more", false},
+		{"Acme | Go Engineer | Remote\nThe role is filled by a new hire every quarter.\nEDIT: typo", false},
+	}
+	for _, tt := range tests {
+		if got := isFilled(tt.text); got != tt.want {
+			t.Errorf("isFilled(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSaysFilled(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"Sorry, the position has been filled.", true},
+		{"We're no longer hiring for this one.", true},
+		{"We have already filled the role", true},
+		{"Filled, thanks everyone!", true},
+		{"We have filled 3 of 5 roles, 2 still open", false},
+		{"Not filled yet, keep applying", false},
+		{"We have filled our seed round and are now hiring", false},
+		{"Positions filled quickly, apply soon!", false},
+		{"We have hired for 3 roles this year; we need 2 more", false},
+	}
+	for _, tt := range tests {
+		if got := saysFilled(tt.text); got != tt.want {
+			t.Errorf("saysFilled(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
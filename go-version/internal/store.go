@@ -0,0 +1,125 @@
+package internal_linkedin_scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// getStoreDir returns where Run keeps the listings of every thread it
+// scraped, for history and trend queries across months. Uses LISTINGS_DIR
+// if set, otherwise /tmp in AWS Lambda, listings/ locally.
+func getStoreDir() string {
+	if dir := os.Getenv("LISTINGS_DIR"); dir != "" {
+		return dir
+	}
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		return "/tmp/listings"
+	}
+	return "listings"
+}
+
+// StoreDir is getStoreDir for the CLI commands that read the store.
+func StoreDir() string {
+	return getStoreDir()
+}
+
+// saveListings stores listings as dir/<month>/<thread>.json, one file per
+// thread. Listings already stored for a thread are kept, except those from a
+// comment that listings has again, so scraping a thread twice, or a page of
+// it at a time, leaves one copy of each comment's listings. Listings without
+// a month or thread aren't stored.
+func saveListings(dir string, listings []Listing) error {
+	type threadKey struct{ month, thread string }
+	byThread := make(map[threadKey][]Listing)
+	for _, l := range listings {
+		if l.Month == "" || l.Thread == "" {
+			continue
+		}
+		key := threadKey{l.Month, l.Thread}
+		byThread[key] = append(byThread[key], l)
+	}
+
+	for key, ls := range byThread {
+		monthDir := filepath.Join(dir, key.month)
+		if err := os.MkdirAll(monthDir, 0755); err != nil {
+			return err
+		}
+		path := filepath.Join(monthDir, key.thread+".json")
+		stored, err := readListings(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		rescraped := make(map[string]bool)
+		for _, l := range ls {
+			if l.CommentID != "" {
+				rescraped[l.CommentID] = true
+			}
+		}
+		stored = slices.DeleteFunc(stored, func(l Listing) bool { return rescraped[l.CommentID] })
+
+		data, err := json.MarshalIndent(append(stored, ls...), "", "  ")
+		if err != nil {
+			return err
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("failed to store listings: %w", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return fmt.Errorf("failed to store listings: %w", err)
+		}
+	}
+	return nil
+}
+
+// readListings reads one stored thread file.
+func readListings(path string) ([]Listing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var listings []Listing
+	if err := json.Unmarshal(data, &listings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return listings, nil
+}
+
+// LoadListings reads every listing stored in dir, oldest month first. A dir
+// that doesn't exist yet holds no listings.
+func LoadListings(dir string) ([]Listing, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".json") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var listings []Listing
+	for _, path := range paths {
+		ls, err := readListings(path)
+		if err != nil {
+			return nil, err
+		}
+		listings = append(listings, ls...)
+	}
+	return listings, nil
+}
@@ -0,0 +1,41 @@
+package internal_linkedin_scraper
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSaveListingsMergesByComment(t *testing.T) {
+	dir := t.TempDir()
+	listing := func(commentID, title string) Listing {
+		return Listing{Month: "2025-06", Thread: "44159528", CommentID: commentID, Title: title}
+	}
+
+	if err := saveListings(dir, []Listing{listing("1", "Go Engineer"), listing("2", "Designer"), listing("2", "PM")}); err != nil {
+		t.Fatal(err)
+	}
+	// a second page of the same thread, with one comment seen again
+	if err := saveListings(dir, []Listing{listing("2", "Product Designer"), listing("3", "SRE")}); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := LoadListings(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, l := range stored {
+		titles = append(titles, l.CommentID+":"+l.Title)
+	}
+	if want := []string{"1:Go Engineer", "2:Product Designer", "3:SRE"}; !slices.Equal(titles, want) {
+		t.Errorf("stored %v, want %v", titles, want)
+	}
+}
+
+func TestLoadListingsWithoutStore(t *testing.T) {
+	listings, err := LoadListings(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || listings != nil {
+		t.Errorf("got %v, %v; want no listings and no error", listings, err)
+	}
+}
@@ -0,0 +1,106 @@
+package internal_linkedin_scraper
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	gpt "github.com/sashabaranov/go-openai"
+	"github.com/tiktoken-go/tokenizer"
+)
+
+const (
+	// bytesPerToken is the rough ratio used for models without a tokenizer
+	bytesPerToken = 4
+
+	// tokensPerMessage is the chat format's overhead for each message
+	tokensPerMessage = 4
+
+	// reservedCompletionTokens is kept free in the context window for the reply
+	reservedCompletionTokens = 1500
+)
+
+// contextWindows is the total context size of the models we use, in tokens.
+var contextWindows = map[string]int{
+	gpt.GPT4:          8192,
+	gpt.GPT4o:         128000,
+	gpt.GPT4oMini:     128000,
+	gpt.GPT4Dot1:      1047576,
+	gpt.GPT3Dot5Turbo: 16385,
+}
+
+var (
+	codecsMu sync.Mutex
+	codecs   = make(map[string]tokenizer.Codec)
+)
+
+// codecFor returns the tokenizer for model, or nil if it isn't known.
+func codecFor(model string) tokenizer.Codec {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if codec, ok := codecs[model]; ok {
+		return codec
+	}
+	codec, err := tokenizer.ForModel(tokenizer.Model(model))
+	if err != nil {
+		codec = nil
+	}
+	codecs[model] = codec
+	return codec
+}
+
+// countTokens returns how many tokens text takes up for model.
+func countTokens(model, text string) int {
+	if codec := codecFor(model); codec != nil {
+		if n, err := codec.Count(text); err == nil {
+			return n
+		}
+	}
+	return (len(text) + bytesPerToken - 1) / bytesPerToken
+}
+
+// countRequestTokens estimates the prompt tokens req will be billed for,
+// including the response schema when one is attached.
+func countRequestTokens(req gpt.ChatCompletionRequest) int {
+	total := 0
+	for _, message := range req.Messages {
+		total += tokensPerMessage + countTokens(req.Model, message.Content)
+	}
+	if req.ResponseFormat != nil && req.ResponseFormat.JSONSchema != nil {
+		schema, _ := json.Marshal(req.ResponseFormat.JSONSchema.Schema)
+		total += countTokens(req.Model, string(schema))
+	}
+	return total
+}
+
+// chunkTokenBudget is how many tokens of data fit in a single request to
+// model alongside systemMessage while leaving room for the reply.
+func chunkTokenBudget(model, systemMessage string) int {
+	window, ok := contextWindows[model]
+	if !ok {
+		window = contextWindows[gpt.GPT4]
+	}
+	return window - countTokens(model, systemMessage) - tokensPerMessage - reservedCompletionTokens
+}
+
+// splitByTokens cuts text into pieces of at most maxTokens tokens on line or
+// word boundaries. It is the last resort for text with no better boundary.
+func splitByTokens(model, text string, maxTokens int) []string {
+	var chunks []string
+	var current strings.Builder
+	currentTokens := 0
+	for _, word := range strings.SplitAfter(text, " ") {
+		wordTokens := countTokens(model, word)
+		if currentTokens+wordTokens > maxTokens && current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+		current.WriteString(word)
+		currentTokens += wordTokens
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
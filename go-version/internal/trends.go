@@ -0,0 +1,358 @@
+package internal_linkedin_scraper
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MinPaySamples is the number of listings stating pay a group needs before
+// AnalyzeTrends reports its median.
+const MinPaySamples = 3
+
+// Trends is the hiring market across the stored months.
+type Trends struct {
+	Months       []string        `json:"months"`
+	Technologies []TechShare     `json:"technologies"`
+	Remote       []RemoteRatio   `json:"remote"`
+	Pay          []PayMedian     `json:"pay"`
+	Locations    []LocationCount `json:"locations"`
+}
+
+// TechShare is the share of a month's listings that mention a technology.
+type TechShare struct {
+	Month      string  `json:"month"`
+	Technology string  `json:"technology"`
+	Listings   int     `json:"listings"`
+	Share      float64 `json:"share"`
+}
+
+// RemoteRatio counts a month's listings by the work policies they allow. A
+// listing open to several, like remote or onsite, counts for each.
+// RemoteShare is the share of listings stating a policy that allow remote.
+type RemoteRatio struct {
+	Month       string  `json:"month"`
+	Listings    int     `json:"listings"`
+	Remote      int     `json:"remote"`
+	Hybrid      int     `json:"hybrid"`
+	Onsite      int     `json:"onsite"`
+	Unstated    int     `json:"unstated"`
+	RemoteShare float64 `json:"remoteShare"`
+}
+
+// PayMedian is the median annualized pay of listings for a technology at a
+// seniority band, or at any band when Seniority is "all". Pay is the middle
+// of each listing's range, or the one end it states, and currencies are
+// kept apart.
+type PayMedian struct {
+	Technology string  `json:"technology"`
+	Seniority  string  `json:"seniority"`
+	Currency   string  `json:"currency"`
+	Listings   int     `json:"listings"`
+	Median     float64 `json:"median"`
+}
+
+// LocationCount is how many listings are based in a place, and in how many
+// months it was hiring.
+type LocationCount struct {
+	Place    string `json:"place"`
+	Listings int    `json:"listings"`
+	Months   int    `json:"months"`
+}
+
+// AnalyzeTrends computes market trends from stored listings. Technologies
+// and locations are limited to the top most mentioned ones overall.
+// Listings without a month are left out.
+func AnalyzeTrends(listings []Listing, top int) Trends {
+	var t Trends
+	perMonth := make(map[string]int)
+	techCount := make(map[string]int)
+	techMonth := make(map[string]map[string]int)
+	spelling := make(map[string]string)
+	remote := make(map[string]*RemoteRatio)
+	type payKey struct{ tech, seniority, currency string }
+	pay := make(map[payKey][]float64)
+	placeCount := make(map[string]int)
+	placeMonths := make(map[string]map[string]bool)
+
+	for _, l := range listings {
+		if l.Month == "" {
+			continue
+		}
+		if perMonth[l.Month] == 0 {
+			t.Months = append(t.Months, l.Month)
+			remote[l.Month] = &RemoteRatio{Month: l.Month}
+		}
+		perMonth[l.Month]++
+
+		var techs []string
+		for _, tech := range splitTechnologies(l.Technologies) {
+			key := strings.ToLower(tech)
+			if slices.Contains(techs, key) {
+				continue
+			}
+			techs = append(techs, key)
+			if _, ok := spelling[key]; !ok {
+				spelling[key] = tech
+			}
+			techCount[key]++
+			if techMonth[key] == nil {
+				techMonth[key] = make(map[string]int)
+			}
+			techMonth[key][l.Month]++
+		}
+
+		r := remote[l.Month]
+		r.Listings++
+		policies := l.WorkLocation.Policies
+		if len(policies) == 0 {
+			r.Unstated++
+		}
+		for _, policy := range policies {
+			switch policy {
+			case PolicyRemote:
+				r.Remote++
+			case PolicyHybrid:
+				r.Hybrid++
+			case PolicyOnsite:
+				r.Onsite++
+			}
+		}
+
+		if !l.Compensation.IsZero() {
+			low, high := l.Compensation.Annualized()
+			mid := low
+			switch {
+			case low == 0:
+				// "up to $200k" only states the top
+				mid = high
+			case high > low:
+				mid = (low + high) / 2
+			}
+			bands := []string{"all"}
+			if l.SeniorityBand != "" {
+				bands = append(bands, l.SeniorityBand)
+			}
+			for _, tech := range techs {
+				for _, band := range bands {
+					key := payKey{tech, band, l.Compensation.Currency}
+					pay[key] = append(pay[key], mid)
+				}
+			}
+		}
+
+		for _, place := range l.WorkLocation.Places {
+			placeCount[place]++
+			if placeMonths[place] == nil {
+				placeMonths[place] = make(map[string]bool)
+			}
+			placeMonths[place][l.Month] = true
+		}
+	}
+	sort.Strings(t.Months)
+
+	topTechs := topKeys(techCount, top)
+	for _, month := range t.Months {
+		for _, tech := range topTechs {
+			n := techMonth[tech][month]
+			t.Technologies = append(t.Technologies, TechShare{
+				Month:      month,
+				Technology: spelling[tech],
+				Listings:   n,
+				Share:      float64(n) / float64(perMonth[month]),
+			})
+		}
+
+		r := remote[month]
+		if stated := r.Listings - r.Unstated; stated > 0 {
+			r.RemoteShare = float64(r.Remote) / float64(stated)
+		}
+		t.Remote = append(t.Remote, *r)
+	}
+
+	for _, tech := range topTechs {
+		var keys []payKey
+		for key, values := range pay {
+			if key.tech == tech && len(values) >= MinPaySamples {
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].currency != keys[j].currency {
+				return keys[i].currency < keys[j].currency
+			}
+			return seniorityOrder(keys[i].seniority) < seniorityOrder(keys[j].seniority)
+		})
+		for _, key := range keys {
+			t.Pay = append(t.Pay, PayMedian{
+				Technology: spelling[tech],
+				Seniority:  key.seniority,
+				Currency:   key.currency,
+				Listings:   len(pay[key]),
+				Median:     median(pay[key]),
+			})
+		}
+	}
+
+	for _, place := range topKeys(placeCount, top) {
+		t.Locations = append(t.Locations, LocationCount{Place: place, Listings: placeCount[place], Months: len(placeMonths[place])})
+	}
+	return t
+}
+
+// topKeys returns the n keys with the highest counts, ties broken by key.
+func topKeys(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if n > 0 && len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+func seniorityOrder(band string) int {
+	return slices.Index([]string{"all", LevelJunior, LevelMid, LevelSenior}, band)
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// Tables lays the trends out as named tables, a header row first, for CSV
+// export and printing.
+func (t Trends) Tables() map[string][][]string {
+	percent := func(v float64) string { return strconv.FormatFloat(v*100, 'f', 1, 64) }
+	itoa := strconv.Itoa
+
+	tech := [][]string{{"month", "technology", "listings", "share"}}
+	for _, s := range t.Technologies {
+		tech = append(tech, []string{s.Month, s.Technology, itoa(s.Listings), percent(s.Share)})
+	}
+	remote := [][]string{{"month", "listings", "remote", "hybrid", "onsite", "unstated", "remoteShare"}}
+	for _, r := range t.Remote {
+		remote = append(remote, []string{r.Month, itoa(r.Listings), itoa(r.Remote), itoa(r.Hybrid), itoa(r.Onsite), itoa(r.Unstated), percent(r.RemoteShare)})
+	}
+	pay := [][]string{{"technology", "seniority", "currency", "listings", "median"}}
+	for _, p := range t.Pay {
+		pay = append(pay, []string{p.Technology, p.Seniority, p.Currency, itoa(p.Listings), strconv.FormatFloat(p.Median, 'f', 0, 64)})
+	}
+	locations := [][]string{{"place", "listings", "months"}}
+	for _, l := range t.Locations {
+		locations = append(locations, []string{l.Place, itoa(l.Listings), itoa(l.Months)})
+	}
+	return map[string][][]string{
+		"technologies": tech,
+		"remote":       remote,
+		"pay":          pay,
+		"locations":    locations,
+	}
+}
+
+// Summary renders the trends as plain text tables.
+func (t Trends) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📈 Trends over %d months", len(t.Months))
+	if len(t.Months) > 0 {
+		fmt.Fprintf(&b, " (%s to %s)", t.Months[0], t.Months[len(t.Months)-1])
+	}
+	b.WriteString("\n")
+	tables := t.Tables()
+	for _, section := range []struct{ name, title string }{
+		{"technologies", "🧰 Technology mention share (%)"},
+		{"remote", "🌍 Remote vs onsite"},
+		{"pay", "💵 Median annual pay"},
+		{"locations", "📍 Top hiring locations"},
+	} {
+		fmt.Fprintf(&b, "\n%s\n", section.title)
+		rows := tables[section.name]
+		widths := make([]int, len(rows[0]))
+		for _, row := range rows {
+			for i, cell := range row {
+				widths[i] = max(widths[i], len([]rune(cell)))
+			}
+		}
+		for _, row := range rows {
+			b.WriteString(" ")
+			for i, cell := range row {
+				fmt.Fprintf(&b, " %-*s", widths[i], cell)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// WriteTrends saves the trends in dir: a CSV file per table, all of them as
+// trends.json, and technology shares over time as technologies.svg. It
+// returns the paths written.
+func WriteTrends(dir string, t Trends) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var paths []string
+	tables := t.Tables()
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(dir, name+".csv")
+		if err := writeCSVRows(path, tables[name]); err != nil {
+			return paths, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return paths, err
+	}
+	path := filepath.Join(dir, "trends.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return paths, err
+	}
+	paths = append(paths, path)
+
+	path = filepath.Join(dir, "technologies.svg")
+	if err := os.WriteFile(path, []byte(TechShareChart(t)), 0644); err != nil {
+		return paths, err
+	}
+	return append(paths, path), nil
+}
+
+func writeCSVRows(path string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	out := csv.NewWriter(file)
+	if err := out.WriteAll(rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
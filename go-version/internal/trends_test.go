@@ -0,0 +1,110 @@
+package internal_linkedin_scraper
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeTrends(t *testing.T) {
+	listings := []Listing{
+		enriched(Listing{Month: "2025-05", Technologies: "Go, Postgres", Location: "Remote (US)", Pay: "$150k-$170k", Seniority: "senior"}),
+		enriched(Listing{Month: "2025-05", Technologies: "Python", Location: "Berlin, onsite", Seniority: "mid"}),
+		enriched(Listing{Month: "2025-06", Technologies: "go, React", Location: "NYC, hybrid", Pay: "$180k-$200k", Seniority: "senior"}),
+		enriched(Listing{Month: "2025-06", Technologies: "Go", Location: "Remote", Pay: "$120k", Seniority: "junior"}),
+		enriched(Listing{Month: "2025-06", Technologies: "React", Location: "San Francisco, onsite"}),
+		enriched(Listing{Month: "2025-06", Technologies: "Go and AWS", Location: "Remote or NYC"}),
+		{Technologies: "Go", Location: "Remote"},
+	}
+
+	trends := AnalyzeTrends(listings, 2)
+	if got := strings.Join(trends.Months, ","); got != "2025-05,2025-06" {
+		t.Fatalf("months %s, want 2025-05,2025-06", got)
+	}
+
+	share := make(map[string]float64)
+	for _, s := range trends.Technologies {
+		share[s.Month+" "+s.Technology] = s.Share
+	}
+	want := map[string]float64{"2025-05 Go": 0.5, "2025-06 Go": 0.75, "2025-05 React": 0, "2025-06 React": 0.5}
+	if len(share) != len(want) {
+		t.Errorf("got shares for %v, want the top 2 technologies per month", share)
+	}
+	for key, w := range want {
+		if share[key] != w {
+			t.Errorf("share of %s = %.2f, want %.2f", key, share[key], w)
+		}
+	}
+
+	june := trends.Remote[1]
+	if june.Listings != 4 || june.Remote != 2 || june.Onsite != 1 || june.Hybrid != 1 {
+		t.Errorf("June policies %+v, want 4 listings: 2 remote, 1 hybrid, 1 onsite", june)
+	}
+
+	var goAll *PayMedian
+	for i, p := range trends.Pay {
+		if p.Seniority != "all" && p.Listings < MinPaySamples {
+			t.Errorf("reported a median for %+v with fewer than %d listings", p, MinPaySamples)
+		}
+		if p.Technology == "Go" && p.Seniority == "all" {
+			goAll = &trends.Pay[i]
+		}
+	}
+	if goAll == nil || goAll.Listings != 3 || goAll.Median != 160000 {
+		t.Errorf("Go median pay %+v, want 160000 over 3 listings", goAll)
+	}
+
+	if len(trends.Locations) == 0 || trends.Locations[0].Place != "New York" || trends.Locations[0].Listings != 2 {
+		t.Errorf("top locations %+v, want New York first with 2 listings", trends.Locations)
+	}
+}
+
+func TestWriteTrends(t *testing.T) {
+	listings := []Listing{
+		{Month: "2025-05", Technologies: "Go"},
+		{Month: "2025-06", Technologies: "Go, Rust & <script>"},
+	}
+	dir := t.TempDir()
+	paths, err := WriteTrends(dir, AnalyzeTrends(listings, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 6 {
+		t.Errorf("wrote %v, want 4 CSV files, JSON and SVG", paths)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "technologies.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1+2*3 || rows[1][1] != "Go" || rows[1][3] != "100.0" {
+		t.Errorf("technologies.csv rows %v", rows)
+	}
+
+	svg, err := os.ReadFile(filepath.Join(dir, "technologies.svg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(svg), "<polyline") != 3 || strings.Contains(string(svg), "<script>") {
+		t.Errorf("chart should have a line per technology and escape their names:\n%s", svg)
+	}
+}
+
+func TestAnalyzeTrendsOneSidedPay(t *testing.T) {
+	var listings []Listing
+	for _, pay := range []string{"up to $200k", "$150k+", "$100k-$120k"} {
+		listings = append(listings, enriched(Listing{Month: "2025-06", Technologies: "Go", Pay: pay}))
+	}
+	trends := AnalyzeTrends(listings, 1)
+	// each listing counts at its stated bound: 200k, 150k and 110k
+	if len(trends.Pay) != 1 || trends.Pay[0].Median != 150000 {
+		t.Errorf("pay medians %+v, want 150000", trends.Pay)
+	}
+}
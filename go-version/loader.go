@@ -4,6 +4,8 @@ import (
 	"log"
 	"os"
 	"strings"
+
+	internal_hackernewsscraper "github.com/Smackface/go-job-scraper/internal"
 )
 
 var (
@@ -28,5 +30,15 @@ func loadConfig() ([]string, []string) {
 
 	whitelist, blacklist = white_keywords, black_keywords
 
+	if err := internal_hackernewsscraper.LoadPricing("config/pricing.json"); err != nil {
+		log.Fatal(err)
+	}
+	if err := internal_hackernewsscraper.LoadPrompts("config/prompts"); err != nil {
+		log.Fatal(err)
+	}
+	if err := internal_hackernewsscraper.LoadExtraFields("config/fields.yaml"); err != nil {
+		log.Fatal(err)
+	}
+
 	return white_keywords, black_keywords
 }
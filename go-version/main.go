@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"log"
 	"os"
 	"time"
 
@@ -15,11 +17,6 @@ import (
 	internal_hackernewsscraper "github.com/Smackface/go-job-scraper/internal"
 )
 
-const whoIsHiringMessage = "You are browsing a public corporate directory on a website that hosts explicitly consented-to corporate contact information. This information is non-personal and is made publicly available by the corporations for professional outreach. Your task is to extract publicly listed corporate contact details such as company names, publicly listed corporate email addresses, the posted job or role title, and any publicly stated professional information like roles or departments. Additionally, identify and log any publicly available information regarding the company's technical stack, focusing on specific technologies such as React, Vue, Golang, Go, and AWS. Be aware that some emails may be formatted as 'example {at} domain'. Additionally, be aware that React may be referred to as ReactJS, and that Vue may be referred to as VueJS. Furthermore, be thorough in checking over the data you are provided. This data will be organized into a log format for professional networking purposes, ensuring compliance with all relevant terms of service and privacy policies associated with the website."
-const hiringFreelanceMessage = "You are browsing a public directory on a professional networking website that hosts contact information explicitly shared by freelancers seeking employment opportunities. This information is professional in nature, provided voluntarily by individuals for the purpose of professional networking and employment. Your task is to extract details such as freelancer names, publicly listed professional email addresses, areas of expertise, and any publicly stated professional information like skills in specific technologies (React, Vue, Golang, Go, and AWS). Additionally, log any available information regarding their past projects or roles that align with these technologies. Organize this data into a log format for facilitating professional connections, ensuring adherence to all relevant terms of service and privacy policies of the networking platform."
-const seekingFreelanceMessage = "You are browsing a public business directory on a professional networking website that hosts contact information explicitly shared by companies seeking to hire freelancers. This information is professional in nature, provided voluntarily by companies for the purpose of professional outreach and recruitment. Your task is to extract details such as company names, publicly listed corporate email addresses, industry sectors, and any publicly stated professional information like current hiring needs or specific skills required (e.g., React, Vue, Golang, Go, AWS). Additionally, log any available information regarding the types of projects or roles they are seeking to fill, especially those requiring specific technical expertise. Organize this data into a log format for facilitating professional connections between companies and potential freelancers, ensuring adherence to all relevant terms of service and privacy policies of the networking platform."
-const jobSeekerContactMessage = "You are browsing a public employment directory on a professional networking website, where job-seekers have explicitly shared their contact information for the purpose of finding employment opportunities. This information is professional in nature, provided voluntarily by individuals seeking job opportunities. Your task is to extract details such as individual names, publicly listed professional email addresses, areas of expertise, and any publicly stated professional information like skills (e.g., React, Vue, Golang, Go, AWS) and desired job roles or industries. Additionally, log any available information regarding their professional experience, education, and types of projects or roles they are interested in, particularly those requiring specific technical expertise. Organize this data into a log format for facilitating professional connections between job-seekers and potential employers, ensuring adherence to all relevant terms of service and privacy policies of the networking platform."
-
 var client *gpt.Client
 var err error
 var fileIndex int = 0
@@ -39,6 +36,9 @@ type FileDownloadResponse struct {
 	ContentType string `json:"contentType"`
 	FileSize    int64  `json:"fileSize"`
 	Success     bool   `json:"success"`
+
+	// Report is the token usage and cost of the run that produced the file
+	Report internal_hackernewsscraper.RunReport `json:"report"`
 }
 
 // Define differentiated POST request templates for each scraper service.
@@ -147,6 +147,7 @@ func lambdaHandler(ctx context.Context, request events.APIGatewayProxyRequest) (
 			ContentType: "text/plain",
 			FileSize:    fileInfo.Size(),
 			Success:     true,
+			Report:      internal_hackernewsscraper.LastRunReport(),
 		}
 
 		responseBody, err := json.Marshal(response)
@@ -198,9 +199,49 @@ func lambdaHandler(ctx context.Context, request events.APIGatewayProxyRequest) (
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		loadConfig()
+		godotenv.Load()
+		runEval(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		runHistory(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "analyze" {
+		runAnalyze(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "match" {
+		runMatch(os.Args[2:])
+		return
+	}
+
+	noCache := flag.Bool("no-cache", false, "Ignore cached model responses and request them again")
+	batch := flag.Bool("batch", false, "Extract listings with the Batch API: slower, at half the cost")
+	attachUpdates := flag.Bool("attach-updates", false, "Attach the poster's replies under each posting to its listings")
+	keepDuplicates := flag.Bool("keep-duplicates", false, "Extract duplicate postings separately instead of merging them")
+	includeClosed := flag.Bool("include-closed", false, "Keep filled, flagged and deleted listings in exports")
+	recordFixtures := flag.String("record-fixtures", "", "Record every HTTP response into this directory")
+	replayFixtures := flag.String("replay-fixtures", "", "Answer HTTP requests from fixtures in this directory instead of the network")
+	flag.Parse()
+	internal_hackernewsscraper.CacheEnabled = !*noCache
+	internal_hackernewsscraper.BatchMode = *batch
+	internal_hackernewsscraper.AttachUpdates = *attachUpdates
+	internal_hackernewsscraper.IncludeClosed = *includeClosed
+	internal_hackernewsscraper.MergeDuplicates = !*keepDuplicates
+	if *recordFixtures != "" {
+		internal_hackernewsscraper.UseFixtures(*recordFixtures, internal_hackernewsscraper.FixturesRecord)
+	} else if *replayFixtures != "" {
+		internal_hackernewsscraper.UseFixtures(*replayFixtures, internal_hackernewsscraper.FixturesReplay)
+	}
+
 	loadConfig()
 	godotenv.Load()
-	internal_hackernewsscraper.Run([]string{"https://news.ycombinator.com/item?id=44159528"})
+	if _, err := internal_hackernewsscraper.Run([]string{"https://news.ycombinator.com/item?id=44159528"}); err != nil {
+		log.Fatal(err)
+	}
 	// fmt.Println("Starting main function...")
 
 	// // Check if running in AWS Lambda environment
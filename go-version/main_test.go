@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	internal_hackernewsscraper "github.com/Smackface/go-job-scraper/internal"
+)
+
+// See internal/hackernewsscraper_test.go for how the fixtures are recorded,
+// and what replaying them does and doesn't check.
+var recordFixtures = flag.Bool("record", false, "record fixtures from the network instead of replaying them")
+
+const fixturesDir = "internal/testdata/fixtures"
+
+func useFixtures(t *testing.T) {
+	t.Helper()
+	mode := internal_hackernewsscraper.FixturesReplay
+	if *recordFixtures {
+		mode = internal_hackernewsscraper.FixturesRecord
+	}
+	dir, err := filepath.Abs(fixturesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(internal_hackernewsscraper.UseFixtures(dir, mode))
+	prevCache := internal_hackernewsscraper.CacheEnabled
+	t.Cleanup(func() { internal_hackernewsscraper.CacheEnabled = prevCache })
+	internal_hackernewsscraper.CacheEnabled = false
+
+	// run in a scratch directory, so the logs the handler consolidates and
+	// deletes are its own, with an empty .env for the scraper to load
+	wd, _ := os.Getwd()
+	scratch := t.TempDir()
+	if err := os.WriteFile(filepath.Join(scratch, ".env"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(scratch); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	if !*recordFixtures {
+		t.Setenv("OPENAI_KEY", "test-key")
+	}
+}
+
+func TestLambdaHandlerHackerNews(t *testing.T) {
+	useFixtures(t)
+
+	request := ScrapeHackerNewsRequest
+	request.Body = `["https://news.ycombinator.com/item?id=44159528"]`
+	res, err := lambdaHandler(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 {
+		t.Fatalf("status = %d, body = %s", res.StatusCode, res.Body)
+	}
+
+	var body FileDownloadResponse
+	if err := json.Unmarshal([]byte(res.Body), &body); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+	if !body.Success || body.Scraper != "hackernews" {
+		t.Errorf("response = %+v, want a successful hackernews scrape", body)
+	}
+	content, err := base64.StdEncoding.DecodeString(body.FileContent)
+	if err != nil {
+		t.Fatalf("file content isn't base64: %v", err)
+	}
+	for _, want := range []string{"Acme Payments", "Globex"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("file is missing %q:\n%s", want, content)
+		}
+	}
+	if body.Report.Pages != 1 || body.Report.PromptTokens == 0 {
+		t.Errorf("report = %+v, want one page with token usage", body.Report)
+	}
+}
+
+func TestLambdaHandlerRejectsBadRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		scraper string
+		body    string
+		status  int
+	}{
+		{"body isn't a list of URLs", "hackernews", `{"url": "x"}`, 400},
+		{"unknown scraper", "indeed", `[]`, 400},
+		{"linkedin is disabled", "linkedin", `[]`, 501},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{
+				Body:                  tt.body,
+				QueryStringParameters: map[string]string{"scraper": tt.scraper},
+			}
+			res, err := lambdaHandler(context.Background(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	internal_hackernewsscraper "github.com/Smackface/go-job-scraper/internal"
+)
+
+// runMatch implements the match command: rank the stored listings against
+// a candidate profile and explain each score.
+//
+//	go-job-scraper match [-profile config/profile.yaml] [-month 2025-06] [-top 20] [-json]
+func runMatch(args []string) {
+	flags := flag.NewFlagSet("match", flag.ExitOnError)
+	dir := flags.String("dir", internal_hackernewsscraper.StoreDir(), "Directory of stored listings")
+	profilePath := flags.String("profile", "config/profile.yaml", "Profile to score listings against")
+	month := flags.String("month", "", "Only score listings from this month, as 2006-01; the latest stored month if empty, every month if \"all\"")
+	top := flags.Int("top", 20, "Number of listings to show, 0 for all")
+	showExcluded := flags.Bool("excluded", false, "Also show excluded listings")
+	includeClosed := flags.Bool("include-closed", false, "Also score filled, flagged and deleted listings")
+	asJSON := flags.Bool("json", false, "Print the matches as JSON")
+	flags.Parse(args)
+
+	profile, err := internal_hackernewsscraper.LoadProfile(*profilePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listings, err := internal_hackernewsscraper.LoadListings(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *month == "" {
+		for _, l := range listings {
+			if l.Month > *month {
+				*month = l.Month
+			}
+		}
+	}
+
+	var candidates []internal_hackernewsscraper.Listing
+	for _, l := range listings {
+		if (*month == "all" || l.Month == *month) && (*includeClosed || !l.IsClosed()) {
+			candidates = append(candidates, l)
+		}
+	}
+
+	var matches []internal_hackernewsscraper.Match
+	for _, m := range profile.Rank(candidates) {
+		if m.Excluded && !*showExcluded {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	if *top > 0 && len(matches) > *top {
+		matches = matches[:*top]
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(matches); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	fmt.Printf("🎯 %d of %d listings from %s\n", len(matches), len(candidates), *month)
+	for _, m := range matches {
+		fmt.Print(m.Explain())
+	}
+}